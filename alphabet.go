@@ -0,0 +1,56 @@
+package orderlyid
+
+import "fmt"
+
+// Alphabet is a set of 32 symbols used to encode the payload and checksum of
+// IDs. The default is the lowercase Crockford alphabet, for which Parse also
+// accepts uppercase and the aliases I, L, O, and U. Custom alphabets, set on a
+// Generator with WithAlphabet, decode only their exact symbols.
+//
+// IDs sort lexically in time order only if the alphabet's symbols are in
+// ascending byte order, as Crockford's are.
+type Alphabet struct {
+	enc   [32]byte
+	dec   [256]byte // symbol value, or 0xFF for bytes outside the alphabet
+	canon [256]bool // symbols accepted in strict mode
+	fold  bool      // compare checksums without regard to ASCII case
+}
+
+// crockford is the default alphabet, set up in init.
+var crockford *Alphabet
+
+// NewAlphabet returns an Alphabet whose symbol values are the positions of
+// the bytes in chars. chars must hold 32 distinct printable ASCII bytes other
+// than '_' and '-', which separate the prefix and checksum.
+//
+// NewAlphabet returns an error wrapping ErrInvalidOption if chars is not a
+// valid alphabet.
+func NewAlphabet(chars string) (*Alphabet, error) {
+	if len(chars) != 32 {
+		return nil, fmt.Errorf("%w: alphabet must have 32 symbols, got %d", ErrInvalidOption, len(chars))
+	}
+	a := new(Alphabet)
+	for i := range a.dec {
+		a.dec[i] = 0xFF
+	}
+	for i := 0; i < len(chars); i++ {
+		c := chars[i]
+		switch {
+		case c <= ' ' || c > '~':
+			return nil, fmt.Errorf("%w: alphabet symbol %q is not printable ASCII", ErrInvalidOption, c)
+		case c == '_' || c == '-':
+			return nil, fmt.Errorf("%w: alphabet symbol %q is a separator", ErrInvalidOption, c)
+		case a.dec[c] != 0xFF:
+			return nil, fmt.Errorf("%w: alphabet symbol %q is repeated", ErrInvalidOption, c)
+		}
+		a.enc[i] = c
+		a.dec[c] = byte(i)
+		a.canon[c] = true
+	}
+	return a, nil
+}
+
+// String returns the 32 symbols of a in value order.
+func (a *Alphabet) String() string {
+	return string(a.enc[:])
+}
@@ -0,0 +1,94 @@
+package orderlyid
+
+import (
+	"errors"
+	mrand "math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAlphabetRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		chars string
+	}{
+		{name: "short", chars: "0123456789abcdefghjkmnpqrstvwxy"},
+		{name: "long", chars: "0123456789abcdefghjkmnpqrstvwxyz!"},
+		{name: "duplicate", chars: "0123456789abcdefghjkmnpqrstvwxy0"},
+		{name: "underscore", chars: "0123456789abcdefghjkmnpqrstvwxy_"},
+		{name: "dash", chars: "0123456789abcdefghjkmnpqrstvwxy-"},
+		{name: "space", chars: "0123456789abcdefghjkmnpqrstvwxy "},
+		{name: "non-ascii", chars: "0123456789abcdefghjkmnpqrstvwxy\xff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAlphabet(tt.chars); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("expected ErrInvalidOption, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAlphabetRoundTrip(t *testing.T) {
+	shuffled := []byte(string(alpha))
+	mrand.New(mrand.NewPCG(1, 2)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	for _, chars := range []string{
+		string(alpha),
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZ234567",
+		"zyxwvtsrqpnmkjhgfedcba9876543210",
+		string(shuffled),
+	} {
+		t.Run(chars, func(t *testing.T) {
+			a, err := NewAlphabet(chars)
+			if err != nil {
+				t.Fatalf("NewAlphabet: %v", err)
+			}
+			if a.String() != chars {
+				t.Fatalf("String = %s, want %s", a, chars)
+			}
+			g := NewGenerator(WithAlphabet(a), WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))))
+			for _, withCS := range []bool{false, true} {
+				id := g.New("order", WithTenant(42), WithShard(0xBEEF), WithChecksum(withCS))
+				payload := id[len("order_") : len("order_")+32]
+				for i := 0; i < len(payload); i++ {
+					if strings.IndexByte(chars, payload[i]) < 0 {
+						t.Fatalf("%s: symbol %q outside the alphabet", id, payload[i])
+					}
+				}
+				p, err := g.Parse(id)
+				if err != nil {
+					t.Fatalf("Parse(%s): %v", id, err)
+				}
+				if p.Tenant != 42 || p.Shard != 0xBEEF || p.HasChecksum != withCS ||
+					p.TimeMs != time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli() {
+					t.Fatalf("unexpected parse result: %+v", p)
+				}
+				again, err := FromBytes(p.Prefix, p.Bytes(), withCS)
+				if err != nil {
+					t.Fatalf("FromBytes: %v", err)
+				}
+				if chars == string(alpha) && again != id {
+					t.Fatalf("Crockford alphabet gives %s, default encoding %s", id, again)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomAlphabetIsCaseSensitive(t *testing.T) {
+	a, err := NewAlphabet("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567")
+	if err != nil {
+		t.Fatalf("NewAlphabet: %v", err)
+	}
+	g := NewGenerator(WithAlphabet(a))
+	id := g.New("order", WithChecksum(true))
+	if _, err := g.Parse(strings.ToLower(id)); !errors.Is(err, ErrInvalidBase32) {
+		t.Fatalf("expected ErrInvalidBase32 for lowercased id, got %v", err)
+	}
+	if _, err := g.NewE("order", WithUppercaseOutput()); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for uppercase output, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package orderlyid
+
+import (
+	"fmt"
+	"io"
+)
+
+// NewBatch generates n IDs using the default Generator, as by
+// Generator.NewBatch.
+func NewBatch(prefix string, n int, opts ...Option) []string {
+	return defaultGenerator.NewBatch(prefix, n, opts...)
+}
+
+// NewBatch generates n IDs with a single acquisition of g's lock, for example
+// to pre-allocate IDs for a bulk insert. The IDs take a contiguous range of
+// the sequence and spill into the following milliseconds when the sequence of
+// a millisecond runs out, without waiting for the clock. The returned IDs are
+// unique and strictly increasing, and IDs generated by g afterwards sort
+// after them. NewBatch returns nil if n <= 0.
+//
+// NewBatch panics under the same conditions as New, and if opts include
+// WithMonotonicDisabled or WithTime, which bypass the sequence.
+func (g *Generator) NewBatch(prefix string, n int, opts ...Option) []string {
+	ids, err := g.newBatch(prefix, n, opts)
+	if err != nil {
+		panic(err)
+	}
+	return ids
+}
+
+func (g *Generator) newBatch(prefix string, n int, opts []Option) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+	o := buildOptions(opts)
+	o.alphabet = g.alphabet
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	if err := o.checkPrefix(prefix); err != nil {
+		return nil, err
+	}
+	if o.noMonotonic || o.hasTime {
+		return nil, fmt.Errorf("%w: NewBatch requires the shared sequence", ErrInvalidOption)
+	}
+	flags, shard, err := g.flagsAndShard(&o)
+	if err != nil {
+		return nil, err
+	}
+
+	size := g.layout.entropyBytes()
+	rnd := make([]byte, size*n)
+	if o.entropy != nil {
+		if _, err := io.ReadFull(o.entropy, rnd); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrEntropy, err)
+		}
+	}
+	bodies := make([][20]byte, n)
+	var (
+		regressed bool
+		wraps     int
+	)
+	g.mu.Lock()
+	raw := g.clock.Now().UnixMilli()
+	ms := o.stamp(raw, g.epoch)
+	st := g.stream(streamKey{prefix, o.tenant, shard}, ms)
+	if err = st.checkJump(&o, raw, g.jumpGuard); err != nil {
+		g.mu.Unlock()
+		return nil, err
+	}
+	ms, regressed = g.hold(st, ms)
+	if o.entropy == nil {
+		err = readEntropy(g.entropy, g.fallback, rnd)
+	}
+	if err == nil {
+		for i := range bodies {
+			// Besides running out, a sequence started by WithSequenceStart
+			// would wrap to 0 and break the ordering; spill in both cases.
+			if ms == st.lastMs && (st.used >= 1<<seqBits || st.seq12 == 0x0FFF) {
+				ms = st.lastMs + 1
+				wraps++
+			}
+			seq := st.take(ms, g.seqBase)
+			random60, shard := g.layout.split(rnd[size*i:], shard)
+			bodies[i] = pack(uint64(ms), flags, o.tenant, seq, shard, random60)
+		}
+	}
+	g.mu.Unlock()
+	if regressed {
+		g.metrics.IncClockRegression()
+	}
+	for range wraps {
+		g.metrics.IncSeqWrap()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, n)
+	for i := range bodies {
+		ids[i] = o.format(prefix, bodies[i])
+		g.metrics.IncIssued()
+	}
+	return ids, nil
+}
@@ -0,0 +1,84 @@
+package orderlyid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewBatchIncreasingAndUnique(t *testing.T) {
+	frozen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		opts     []GeneratorOption
+		n        int
+		lastMs   int64
+		firstSeq uint16
+	}{
+		{name: "single ms", n: 100, lastMs: 0, firstSeq: 0},
+		{name: "spills", n: 10000, lastMs: 2, firstSeq: 0},
+		{name: "sequence start", opts: []GeneratorOption{WithSequenceStart(4000)}, n: 200, lastMs: 2, firstSeq: 4000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerator(append(tt.opts, WithClock(fixedClock(frozen)))...)
+			ids := g.NewBatch("order", tt.n, WithTenant(3), WithChecksum(true))
+			if len(ids) != tt.n {
+				t.Fatalf("len = %d, want %d", len(ids), tt.n)
+			}
+			seen := make(map[string]bool, len(ids))
+			for i, id := range ids {
+				if i > 0 && id <= ids[i-1] {
+					t.Fatalf("id %d: %s not above %s", i, id, ids[i-1])
+				}
+				if seen[id] {
+					t.Fatalf("id %d: duplicate %s", i, id)
+				}
+				seen[id] = true
+			}
+			if p := mustParse(t, ids[0]); p.TimeMs != frozen.UnixMilli() || p.Seq != tt.firstSeq || p.Tenant != 3 {
+				t.Fatalf("first id = %+v", p)
+			}
+			last := mustParse(t, ids[len(ids)-1])
+			if last.TimeMs != frozen.UnixMilli()+tt.lastMs {
+				t.Fatalf("last time = %d, want %d ms past %d", last.TimeMs, tt.lastMs, frozen.UnixMilli())
+			}
+			if next := g.New("order", WithTenant(3), WithChecksum(true)); next <= ids[len(ids)-1] {
+				t.Fatalf("New after batch %s does not sort after %s", next, ids[len(ids)-1])
+			}
+		})
+	}
+}
+
+func TestNewBatchEdgeCases(t *testing.T) {
+	g := NewGenerator()
+	if ids := g.NewBatch("order", 0); ids != nil {
+		t.Fatalf("NewBatch(0) = %v, want nil", ids)
+	}
+	if _, err := g.newBatch("order", 2, []Option{WithMonotonicDisabled()}); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := g.newBatch("Order", 2, nil); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+	if _, err := NewGenerator(WithEntropy(failingReader{})).newBatch("order", 2, nil); !errors.Is(err, ErrEntropy) {
+		t.Fatalf("expected ErrEntropy, got %v", err)
+	}
+}
+
+func BenchmarkNewBatch1000(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewBatch("order", 1000)
+	}
+}
+
+func BenchmarkNewLoop1000(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ids := make([]string, 1000)
+		for j := range ids {
+			ids[j] = New("order")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package orderlyid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Checksum returns the 4-character checksum of id, which must be a
+// "prefix_payload" string without a checksum suffix. Uppercase payload
+// characters and the aliases I, L, O, and U are accepted and yield the same
+// checksum as the canonical form; the result is always lowercase. Appending
+// "-" and the result to the canonical form of id gives the checksummed ID.
+//
+// Checksum returns an error wrapping ErrInvalidFormat if id already carries a
+// checksum suffix, and otherwise any error returned by Parse.
+func Checksum(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if strings.IndexByte(id, '-') >= 0 {
+		return "", fmt.Errorf("%w: id already carries a checksum suffix", ErrInvalidFormat)
+	}
+	var p Parsed
+	if err := parseInto(id, &p, false); err != nil {
+		return "", err
+	}
+	cs := checksum4(p.Prefix, id[len(p.Prefix)+1:])
+	return string(cs[:]), nil
+}
+
+// VerifyChecksum checks an ID that carries a checksum suffix. The suffix is
+// compared without regard to case.
+//
+// VerifyChecksum returns an error wrapping ErrInvalidFormat if id has no
+// checksum suffix, ErrInvalidChecksum if the suffix does not match, and
+// otherwise any error returned by Parse.
+func VerifyChecksum(id string) error {
+	id = strings.TrimSpace(id)
+	if strings.IndexByte(id, '-') < 0 {
+		return fmt.Errorf("%w: missing checksum suffix", ErrInvalidFormat)
+	}
+	var p Parsed
+	return parseInto(id, &p, false)
+}
@@ -0,0 +1,183 @@
+package orderlyid
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportedPolymodMatchesChecksum(t *testing.T) {
+	if got, want := HRPExpand("ab_"), []byte{3, 3, 2, 0, 1, 2, 31}; !slices.Equal(got, want) {
+		t.Fatalf("HRPExpand = %v, want %v", got, want)
+	}
+	for _, prefix := range []string{"order", "u1", strings.Repeat("x", 31)} {
+		id := New(prefix, WithChecksum(true))
+		base, cs, _ := strings.Cut(id, "-")
+		payload := base[len(prefix)+1:]
+
+		values := HRPExpand(prefix + "_")
+		for i := 0; i < len(payload); i++ {
+			values = append(values, byte(strings.IndexByte(string(alpha), payload[i])))
+		}
+		values = append(values, 0, 0, 0, 0)
+		pm := Polymod(values) ^ 1
+
+		var got [4]byte
+		for i := range got {
+			got[i] = alpha[(pm>>(5*(3-i)))&31]
+		}
+		if string(got[:]) != cs {
+			t.Fatalf("%s: exported primitives give checksum %s, want %s", id, got, cs)
+		}
+	}
+}
+
+func TestChecksumAndVerify(t *testing.T) {
+	id := New("order", WithChecksum(true))
+	base, want, _ := strings.Cut(id, "-")
+	payload := base[len("order_"):]
+	// An alias-spelled payload: canonical 0, 1 and v written as O, L and U.
+	alias := strings.NewReplacer("0", "O", "1", "L", "v", "U").Replace(payload)
+
+	for _, in := range []string{
+		base,
+		"order_" + strings.ToUpper(payload),
+		"order_" + alias,
+	} {
+		got, err := Checksum(in)
+		if err != nil {
+			t.Fatalf("Checksum(%q): %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("Checksum(%q) = %s, want %s", in, got, want)
+		}
+		if err := VerifyChecksum(in + "-" + strings.ToUpper(want)); err != nil {
+			t.Fatalf("VerifyChecksum(%q): %v", in, err)
+		}
+	}
+
+	if _, err := Checksum(id); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("Checksum of checksummed id: expected ErrInvalidFormat, got %v", err)
+	}
+	if _, err := Checksum("order_123"); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+	if err := VerifyChecksum(base); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("VerifyChecksum without suffix: expected ErrInvalidFormat, got %v", err)
+	}
+	bad := "0000"
+	if bad == want {
+		bad = "1111"
+	}
+	if err := VerifyChecksum(base + "-" + bad); !errors.Is(err, ErrInvalidChecksum) {
+		t.Fatalf("expected ErrInvalidChecksum, got %v", err)
+	}
+}
+
+// refChecksum recomputes an n-symbol checksum from the exported Bech32
+// primitives (n = 4 or 6) or a direct CashAddr polymod over the same values
+// (n = 8).
+func refChecksum(prefix, payload string, n int) string {
+	values := HRPExpand(prefix + "_")
+	for i := 0; i < len(payload); i++ {
+		values = append(values, byte(strings.IndexByte(string(alpha), payload[i])))
+	}
+	values = append(values, make([]byte, n)...)
+	var pm uint64
+	if n == 8 {
+		gen := [5]uint64{0x98f2bc8e61, 0x79b76d99e2, 0xf33e5fb3c4, 0xae2eabe2a8, 0x1e4f43e470}
+		c := uint64(1)
+		for _, v := range values {
+			b := c >> 35
+			c = (c&0x07ffffffff)<<5 ^ uint64(v)
+			for i, g := range gen {
+				if b>>i&1 != 0 {
+					c ^= g
+				}
+			}
+		}
+		pm = c ^ 1
+	} else {
+		pm = uint64(Polymod(values) ^ 1)
+	}
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = alpha[(pm>>(5*(n-1-i)))&31]
+	}
+	return string(out)
+}
+
+func TestChecksumLenVectors(t *testing.T) {
+	lens := map[int]int{}
+	for _, v := range loadVectors(t).Vectors {
+		n := v.checksumLen()
+		if v.ExpectError || n == 0 {
+			continue
+		}
+		lens[n]++
+		t.Run(v.ID, func(t *testing.T) {
+			base, _, _ := strings.Cut(v.ID, "-")
+			if ref := base + "-" + refChecksum(v.Prefix, base[len(v.Prefix)+1:], n); ref != v.ID {
+				t.Fatalf("reference checksum gives %s", ref)
+			}
+			p := mustParse(t, v.ID)
+			if p.ChecksumLen != n || !p.HasChecksum {
+				t.Fatalf("ChecksumLen = %d, HasChecksum = %v; want %d, true", p.ChecksumLen, p.HasChecksum, n)
+			}
+			if got, err := p.String(); err != nil || got != v.ID {
+				t.Fatalf("String = %s, %v; want %s", got, err, v.ID)
+			}
+			if got, err := Canonicalize(v.Prefix + strings.ToUpper(v.ID[len(v.Prefix):])); err != nil || got != v.ID {
+				t.Fatalf("Canonicalize = %s, %v; want %s", got, err, v.ID)
+			}
+			if at := time.UnixMilli(v.TimeMs); !p.Time().Equal(at) {
+				t.Fatalf("Time = %v, want %v", p.Time(), at)
+			}
+		})
+	}
+	for _, n := range []int{4, 6, 8} {
+		if lens[n] == 0 {
+			t.Errorf("spec vectors have no %d-char checksum", n)
+		}
+	}
+}
+
+func TestWithChecksumLen(t *testing.T) {
+	for _, n := range []int{4, 6, 8} {
+		id := New("order", WithTenant(3), WithChecksumLen(n))
+		base, cs, ok := strings.Cut(id, "-")
+		if !ok || len(cs) != n {
+			t.Fatalf("WithChecksumLen(%d) gave %s", n, id)
+		}
+		if err := VerifyChecksum(id); err != nil {
+			t.Fatalf("VerifyChecksum(%s): %v", id, err)
+		}
+		// Any substituted checksum symbol is detected.
+		for i := 0; i < n; i++ {
+			b := []byte(cs)
+			b[i] = alpha[(strings.IndexByte(string(alpha), b[i])+1)%32]
+			if _, err := Parse(base + "-" + string(b)); !errors.Is(err, ErrInvalidChecksum) {
+				t.Fatalf("tampered %s-%s: expected ErrInvalidChecksum, got %v", base, b, err)
+			}
+		}
+		if got := FindAllAny("see " + id + ", thanks"); !slices.Equal(got, []string{id}) {
+			t.Fatalf("FindAllAny = %v, want [%s]", got, id)
+		}
+		if got := New("order", WithChecksumLen(n), WithChecksum(false)); strings.Contains(got, "-") {
+			t.Fatalf("WithChecksum(false) after WithChecksumLen kept a checksum: %s", got)
+		}
+	}
+	for _, n := range []int{-1, 2, 5, 10} {
+		if _, err := NewE("order", WithChecksumLen(n)); !errors.Is(err, ErrInvalidOption) {
+			t.Fatalf("WithChecksumLen(%d): expected ErrInvalidOption, got %v", n, err)
+		}
+	}
+	base := New("order")
+	for _, cs := range []string{"abc", "abcde", "abcdefg", "abcdefghj"} {
+		if _, err := Parse(base + "-" + cs); !errors.Is(err, ErrInvalidChecksum) {
+			t.Fatalf("%d-char checksum: expected ErrInvalidChecksum, got %v", len(cs), err)
+		}
+	}
+}
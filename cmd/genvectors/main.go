@@ -0,0 +1,195 @@
+// Command genvectors synthesizes a test-vectors.json covering the boundaries of
+// the OrderlyID layout: epoch and 48-bit time limits, max tenant and shard,
+// sequence 0 and 4095, all-zero and all-ones random values, without a checksum
+// and with each checksum length, plus crafted invalid cases.
+//
+// Usage:
+//
+//	go run ./cmd/genvectors -out spec/test-vectors.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	orderlyid "github.com/orderlykit/orderlyid"
+)
+
+type specVectors struct {
+	Vectors []vector `json:"vectors"`
+}
+
+type vector struct {
+	Desc        string `json:"desc"`
+	Prefix      string `json:"prefix"`
+	TimeMs      int64  `json:"time_ms"`
+	Flags       uint8  `json:"flags"`
+	Tenant      uint16 `json:"tenant"`
+	Seq         uint16 `json:"seq"`
+	Shard       uint16 `json:"shard"`
+	RandomHex   string `json:"random_hex"`
+	ID          string `json:"id"`
+	ChecksumLen int    `json:"checksum_len,omitempty"`
+	ExpectError bool   `json:"expect_error,omitempty"`
+}
+
+const (
+	epoch2020 = int64(1577836800000)
+	maxTimeMs = epoch2020 + (1<<48 - 1)
+)
+
+func main() {
+	out := flag.String("out", "", "write vectors to this path instead of stdout")
+	flag.Parse()
+
+	vs, err := generate()
+	must(err)
+	b, err := json.MarshalIndent(specVectors{Vectors: vs}, "", "  ")
+	must(err)
+	b = append(b, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(b)
+		must(err)
+		return
+	}
+	must(os.WriteFile(*out, b, 0o644))
+	fmt.Fprintf(os.Stderr, "wrote %d vectors to %s\n", len(vs), *out)
+}
+
+// generate returns the valid boundary vectors followed by invalid vectors
+// derived from them.
+func generate() ([]vector, error) {
+	times := []struct {
+		name string
+		ms   int64
+	}{
+		{"epoch", epoch2020},
+		{"2025", 1735689600123},
+		{"max time", maxTimeMs},
+	}
+	randoms := []struct {
+		name string
+		hex  string
+	}{
+		{"zero random", "0000000000000000"},
+		{"ones random", "0fffffffffffffff"},
+		{"mixed random", "0123456789abcdef"},
+	}
+	fields := []struct {
+		name               string
+		flags              uint8
+		tenant, seq, shard uint16
+	}{
+		{"zero fields", 0, 0, 0, 0},
+		{"max tenant/shard", 0, 65535, 0, 65535},
+		{"seq=4095", 0, 1, 4095, 7},
+		{"privacy flag", orderlyid.FlagPrivacy, 42, 1, 2},
+	}
+
+	var vs []vector
+	for _, tm := range times {
+		for _, r := range randoms {
+			for _, f := range fields {
+				for _, n := range []int{0, 4, 6, 8} {
+					v := vector{
+						Desc:        fmt.Sprintf("%s, %s, %s, checksum=%d", tm.name, f.name, r.name, n),
+						Prefix:      "order",
+						TimeMs:      tm.ms,
+						Flags:       f.flags,
+						Tenant:      f.tenant,
+						Seq:         f.seq,
+						Shard:       f.shard,
+						RandomHex:   r.hex,
+						ChecksumLen: n,
+					}
+					id, err := encode(v)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", v.Desc, err)
+					}
+					v.ID = id
+					vs = append(vs, v)
+				}
+			}
+		}
+	}
+
+	for _, p := range []string{"ab", "z9y8x7w6v5u4t3s2r1q0p9o8n7m6l5k"} {
+		v := vector{Desc: fmt.Sprintf("prefix length %d", len(p)), Prefix: p, TimeMs: epoch2020, RandomHex: "0000000000000001", ChecksumLen: 4}
+		id, err := encode(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Desc, err)
+		}
+		v.ID = id
+		vs = append(vs, v)
+	}
+
+	base := vs[len(vs)-1]
+	plain := strings.SplitN(base.ID, "-", 2)[0]
+	checksum := base.ID[len(plain)+1:]
+	payload := plain[strings.IndexByte(plain, '_')+1:]
+	invalid := []struct {
+		desc string
+		id   string
+	}{
+		{"invalid: bad checksum", plain + "-" + flip(checksum)},
+		{"invalid: checksum too short", plain + "-" + checksum[:3]},
+		{"invalid: checksum too long", plain + "-" + checksum + "0"},
+		{"invalid: payload too short", base.Prefix + "_" + payload[:31]},
+		{"invalid: payload too long", base.Prefix + "_" + payload + "0"},
+		{"invalid: base32 character", base.Prefix + "_" + payload[:31] + "!"},
+		{"invalid: missing separator", base.Prefix + payload},
+		{"invalid: uppercase prefix", strings.ToUpper(base.Prefix) + "_" + payload},
+		{"invalid: prefix too short", "a_" + payload},
+		{"invalid: prefix starts with digit", "1ab_" + payload},
+	}
+	for _, in := range invalid {
+		v := base
+		v.Desc = in.desc
+		v.ID = in.id
+		v.ExpectError = true
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// encode builds the ID for the fields of v with its checksum length.
+func encode(v vector) (string, error) {
+	id, err := orderlyid.NewFromPartsHex(orderlyid.Components{
+		Prefix: v.Prefix,
+		TimeMs: v.TimeMs,
+		Flags:  v.Flags,
+		Tenant: v.Tenant,
+		Seq:    v.Seq,
+		Shard:  v.Shard,
+	}, v.RandomHex, false)
+	if err != nil || v.ChecksumLen == 0 {
+		return id, err
+	}
+	// NewFromPartsHex only appends 4-char checksums; re-encode through
+	// Parsed to honor the vector's length.
+	p, err := orderlyid.Parse(id)
+	if err != nil {
+		return "", err
+	}
+	p.HasChecksum, p.ChecksumLen = true, v.ChecksumLen
+	return p.String()
+}
+
+func flip(s string) string {
+	last := s[len(s)-1]
+	repl := byte('0')
+	if last == '0' {
+		repl = '1'
+	}
+	return s[:len(s)-1] + string(repl)
+}
+
+func must(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
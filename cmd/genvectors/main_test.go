@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	orderlyid "github.com/orderlykit/orderlyid"
+)
+
+// TestGeneratedVectorsPassConformance applies the same encode and parse checks
+// as tools/conformance to every generated vector.
+func TestGeneratedVectorsPassConformance(t *testing.T) {
+	vs, err := generate()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	var valid, invalid int
+	seen := map[string]bool{}
+	for _, v := range vs {
+		if seen[v.ID] {
+			t.Fatalf("[%s] duplicate id %s", v.Desc, v.ID)
+		}
+		seen[v.ID] = true
+
+		parsed, err := orderlyid.Parse(v.ID)
+		if v.ExpectError {
+			invalid++
+			if err == nil {
+				t.Fatalf("[%s] expected parse error for %s", v.Desc, v.ID)
+			}
+			continue
+		}
+		valid++
+		if err != nil {
+			t.Fatalf("[%s] parse: %v", v.Desc, err)
+		}
+
+		got, err := encode(v)
+		if err != nil {
+			t.Fatalf("[%s] encode: %v", v.Desc, err)
+		}
+		if got != v.ID {
+			t.Fatalf("[%s] encode mismatch:\n got: %s\nwant: %s", v.Desc, got, v.ID)
+		}
+		want := orderlyid.Parsed{
+			Prefix:      v.Prefix,
+			TimeMs:      v.TimeMs,
+			Flags:       v.Flags,
+			Tenant:      v.Tenant,
+			Seq:         v.Seq & 0x0FFF,
+			Shard:       v.Shard,
+			Random:      parsed.Random,
+			HasChecksum: v.ChecksumLen > 0,
+			ChecksumLen: v.ChecksumLen,
+		}
+		if got := strings.Contains(v.ID, "-"); got != want.HasChecksum {
+			t.Fatalf("[%s] checksum suffix present = %v, checksum_len = %d", v.Desc, got, v.ChecksumLen)
+		}
+		if *parsed != want {
+			t.Fatalf("[%s] parse mismatch:\n got: %+v\nwant: %+v", v.Desc, *parsed, want)
+		}
+		if parsed.RandomHex() != v.RandomHex {
+			t.Fatalf("[%s] random mismatch: got=%s want=%s", v.Desc, parsed.RandomHex(), v.RandomHex)
+		}
+	}
+	if valid == 0 || invalid == 0 {
+		t.Fatalf("expected valid and invalid vectors, got %d valid and %d invalid", valid, invalid)
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
@@ -24,12 +27,47 @@ type vector struct {
 	Shard       uint16 `json:"shard"`
 	RandomHex   string `json:"random_hex"`
 	ID          string `json:"id"`
-	ExpectError bool   `json:"expect_error"`
+	ChecksumLen int    `json:"checksum_len,omitempty"`
+	ExpectError bool   `json:"expect_error,omitempty"`
+}
+
+// checksumLen returns the checksum length of vec: its checksum_len, or for
+// vectors that predate that field, 4 if the id has a suffix.
+func (vec vector) checksumLen() int {
+	if vec.ChecksumLen == 0 && strings.Contains(vec.ID, "-") {
+		return 4
+	}
+	return vec.ChecksumLen
+}
+
+// packedVector pairs a valid vector with its 20-byte packed body so that other
+// implementations can diff their pack output byte for byte.
+type packedVector struct {
+	Desc        string `json:"desc"`
+	Prefix      string `json:"prefix"`
+	TimeMs      int64  `json:"time_ms"`
+	Flags       uint8  `json:"flags"`
+	Tenant      uint16 `json:"tenant"`
+	Seq         uint16 `json:"seq"`
+	Shard       uint16 `json:"shard"`
+	RandomHex   string `json:"random_hex"`
+	ID          string `json:"id"`
+	ChecksumLen int    `json:"checksum_len,omitempty"`
+	BodyHex     string `json:"body_hex"`
+}
+
+type packedVectors struct {
+	Vectors []packedVector `json:"vectors"`
 }
 
 const epoch2020 = int64(1577836800000)
 
 func main() {
+	packed := flag.String("packed", "", "also write packed-body golden vectors to this path (e.g. spec/test-vectors-packed.json)")
+	add := flag.Int("add", 0, "append N random vectors, each with and without a checksum")
+	seed := flag.Int64("seed", 1, "random seed for -add")
+	flag.Parse()
+
 	path := filepath.Join("spec", "test-vectors.json")
 	b, err := os.ReadFile(path)
 	must(err)
@@ -45,19 +83,12 @@ func main() {
 			continue
 		}
 
-		withChecksum := strings.Contains(vec.ID, "-")
-		got, err := orderlyid.NewFromPartsHex(
-			orderlyid.Components{
-				Prefix: vec.Prefix,
-				TimeMs: vec.TimeMs,
-				Flags:  vec.Flags,
-				Tenant: vec.Tenant,
-				Seq:    vec.Seq,
-				Shard:  vec.Shard,
-			},
-			vec.RandomHex,
-			withChecksum,
-		)
+		if n := vec.checksumLen(); vec.ChecksumLen != n {
+			fmt.Printf("update [%s]\n  checksum_len: %d\n", vec.Desc, n)
+			vec.ChecksumLen = n
+			changed = true
+		}
+		got, err := encode(*vec)
 		must(err)
 		if vec.ID != got {
 			fmt.Printf("update [%s]\n  old: %s\n  new: %s\n", vec.Desc, vec.ID, got)
@@ -66,13 +97,132 @@ func main() {
 		}
 	}
 
+	if *add > 0 {
+		seen := make(map[string]bool, len(v.Vectors))
+		for _, vec := range v.Vectors {
+			seen[vec.ID] = true
+		}
+		fresh, err := randomVectors(*add, *seed)
+		must(err)
+		for _, vec := range fresh {
+			// Rerunning with the same seed yields the same IDs; keep one copy.
+			if seen[vec.ID] {
+				continue
+			}
+			fmt.Printf("add [%s]\n  id: %s\n", vec.Desc, vec.ID)
+			v.Vectors = append(v.Vectors, vec)
+			changed = true
+		}
+	}
+
+	if *packed != "" {
+		writePacked(*packed, v)
+	}
+
 	if !changed {
 		fmt.Println("no changes")
 		return
 	}
 	out, err := json.MarshalIndent(v, "", "  ")
 	must(err)
-	must(os.WriteFile(path, out, 0o644))
+	must(os.WriteFile(path, append(out, '\n'), 0o644))
+	fmt.Printf("wrote %s\n", path)
+}
+
+// encode builds the ID for the fields of vec with its checksum length.
+func encode(vec vector) (string, error) {
+	id, err := orderlyid.NewFromPartsHex(
+		orderlyid.Components{
+			Prefix: vec.Prefix,
+			TimeMs: vec.TimeMs,
+			Flags:  vec.Flags,
+			Tenant: vec.Tenant,
+			Seq:    vec.Seq,
+			Shard:  vec.Shard,
+		},
+		vec.RandomHex,
+		false,
+	)
+	n := vec.checksumLen()
+	if err != nil || n == 0 {
+		return id, err
+	}
+	// NewFromPartsHex only appends 4-char checksums; re-encode through
+	// Parsed to honor the vector's length.
+	p, err := orderlyid.Parse(id)
+	if err != nil {
+		return "", err
+	}
+	p.HasChecksum, p.ChecksumLen = true, n
+	return p.String()
+}
+
+// randomPrefixes are the prefixes drawn for random vectors.
+var randomPrefixes = []string{"order", "user", "invoice", "acct", "evt2"}
+
+// randomTimeSpan is the range of random vector times, about 20 years from
+// 2020-01-01.
+const randomTimeSpan = int64(20 * 365.25 * 24 * 3600 * 1000)
+
+// randomVectors returns n random component sets drawn deterministically from
+// seed, each as a vector without and a vector with a checksum.
+func randomVectors(n int, seed int64) ([]vector, error) {
+	r := rand.New(rand.NewSource(seed))
+	out := make([]vector, 0, 2*n)
+	for i := 0; i < n; i++ {
+		var rnd [8]byte
+		r.Read(rnd[:])
+		rnd[0] &= 0x0F // keep the 60 random bits
+		base := vector{
+			Prefix:    randomPrefixes[r.Intn(len(randomPrefixes))],
+			TimeMs:    epoch2020 + r.Int63n(randomTimeSpan),
+			Tenant:    uint16(r.Intn(1 << 16)),
+			Seq:       uint16(r.Intn(1 << 12)),
+			Shard:     uint16(r.Intn(1 << 16)),
+			RandomHex: hex.EncodeToString(rnd[:]),
+		}
+		for _, n := range []int{0, 4} {
+			vec := base
+			vec.Desc = fmt.Sprintf("random seed=%d #%d, checksum=%v", seed, i, n > 0)
+			vec.ChecksumLen = n
+			id, err := encode(vec)
+			if err != nil {
+				return nil, err
+			}
+			vec.ID = id
+			out = append(out, vec)
+		}
+	}
+	return out, nil
+}
+
+// writePacked writes the packed body of every valid vector to path.
+func writePacked(path string, v specVectors) {
+	var pv packedVectors
+	for _, vec := range v.Vectors {
+		if vec.ExpectError {
+			continue
+		}
+		p, err := orderlyid.Parse(vec.ID)
+		must(err)
+		body := p.Bytes()
+		pv.Vectors = append(pv.Vectors, packedVector{
+			Desc:        vec.Desc,
+			Prefix:      vec.Prefix,
+			TimeMs:      vec.TimeMs,
+			Flags:       vec.Flags,
+			Tenant:      vec.Tenant,
+			Seq:         vec.Seq,
+			Shard:       vec.Shard,
+			RandomHex:   vec.RandomHex,
+			ID:          vec.ID,
+			ChecksumLen: vec.ChecksumLen,
+			BodyHex:     hex.EncodeToString(body[:]),
+		})
+	}
+	out, err := json.MarshalIndent(pv, "", "  ")
+	must(err)
+	must(os.WriteFile(path, append(out, '\n'), 0o644))
 	fmt.Printf("wrote %s\n", path)
 }
 
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	orderlyid "github.com/orderlykit/orderlyid"
+)
+
+func TestRandomVectorsAreDeterministicAndValid(t *testing.T) {
+	a, err := randomVectors(50, 7)
+	if err != nil {
+		t.Fatalf("randomVectors: %v", err)
+	}
+	b, err := randomVectors(50, 7)
+	if err != nil {
+		t.Fatalf("randomVectors: %v", err)
+	}
+	if !slices.Equal(a, b) {
+		t.Fatalf("same seed produced different vectors")
+	}
+	c, err := randomVectors(50, 8)
+	if err != nil {
+		t.Fatalf("randomVectors: %v", err)
+	}
+	if slices.Equal(a, c) {
+		t.Fatalf("different seeds produced the same vectors")
+	}
+	if len(a) != 100 {
+		t.Fatalf("got %d vectors, want 100", len(a))
+	}
+
+	for i, v := range a {
+		if got, want := strings.Contains(v.ID, "-"), i%2 == 1; got != want {
+			t.Fatalf("[%s] checksum present = %v, want %v", v.Desc, got, want)
+		}
+		p, err := orderlyid.Parse(v.ID)
+		if err != nil {
+			t.Fatalf("[%s] parse: %v", v.Desc, err)
+		}
+		if p.Prefix != v.Prefix || p.TimeMs != v.TimeMs || p.Tenant != v.Tenant ||
+			p.Seq != v.Seq || p.Shard != v.Shard || p.RandomHex() != v.RandomHex {
+			t.Fatalf("[%s] parse mismatch: %+v", v.Desc, p)
+		}
+		if !slices.Contains(randomPrefixes, v.Prefix) {
+			t.Fatalf("[%s] unexpected prefix %q", v.Desc, v.Prefix)
+		}
+	}
+}
@@ -1,66 +1,111 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	oi "github.com/orderlykit/orderlyid"
 )
 
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command with args and returns the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("orderlyid", flag.ContinueOnError)
+	fs.SetOutput(stderr)
 	var (
-		prefix    = flag.String("prefix", "", "type prefix (e.g., order, user)")
-		tenant    = flag.Uint("tenant", 0, "tenant id (0-65535)")
-		shard     = flag.Uint("shard", 0, "shard id (0-65535)")
-		shardFrom = flag.String("shard-from", "", "derive shard from bytes of this string (overrides -shard)")
-		checksum  = flag.Bool("checksum", false, "append 4-char checksum")
-		bucket    = flag.Int("bucket", 0, "bucket seconds for time privacy (0 = none)")
-		count     = flag.Int("n", 1, "how many IDs to generate")
-		parseOnly = flag.String("parse", "", "parse and inspect an existing OrderlyID")
-		tamper    = flag.String("tamper", "", "modify the last char and attempt parse (should fail)")
+		prefix    = fs.String("prefix", "", "type prefix (e.g., order, user)")
+		tenant    = fs.Uint("tenant", 0, "tenant id (0-65535)")
+		shard     = fs.Uint("shard", 0, "shard id (0-65535)")
+		shardFrom = fs.String("shard-from", "", "derive shard from bytes of this string (overrides -shard)")
+		checksum  = fs.Bool("checksum", false, "append 4-char checksum")
+		bucket    = fs.Int("bucket", 0, "bucket seconds for time privacy (0 = none)")
+		count     = fs.Int("n", 1, "how many IDs to generate")
+		at        = fs.String("time", "", "generate at this RFC3339 time or Unix ms instead of now")
+		parseOnly = fs.String("parse", "", "parse and inspect an existing OrderlyID, or - to validate one ID per line of stdin")
+		tamper    = fs.String("tamper", "", "modify the last char and attempt parse (should fail)")
+		sortIn    = fs.Bool("sort", false, "print the IDs read from stdin in sort order, ignoring checksums")
+		strict    = fs.Bool("strict", false, "with -sort, exit non-zero instead of skipping invalid lines")
+		asJSON    = fs.Bool("json", false, "print one JSON object per ID (NDJSON when generating several)")
+		field     = fs.String("field", "", "with -parse, print only this component: "+strings.Join(fieldNames, ", "))
 	)
-	flag.Parse()
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *field != "" {
+		switch {
+		case !slices.Contains(fieldNames, *field):
+			fmt.Fprintf(stderr, "unknown -field %q: want one of %s\n", *field, strings.Join(fieldNames, ", "))
+			return 2
+		case *asJSON:
+			fmt.Fprintln(stderr, "-field and -json cannot be combined")
+			return 2
+		}
+	}
 
 	// Tamper demo: flips last char so checksum fails.
 	if *tamper != "" {
 		bad := *tamper
-		if len(bad) == 0 {
-			log.Fatalf("empty -tamper value")
-		}
 		last := bad[len(bad)-1]
 		repl := byte('0')
 		if last == '0' {
 			repl = '1'
 		}
 		bad = bad[:len(bad)-1] + string(repl)
-		fmt.Println("Tampered:", bad)
+		fmt.Fprintln(stdout, "Tampered:", bad)
 		if _, err := oi.Parse(bad); err != nil {
-			fmt.Println("Parse error (expected):", err)
+			fmt.Fprintln(stdout, "Parse error (expected):", err)
 		} else {
-			fmt.Println("Unexpected: checksum accepted")
-			os.Exit(1)
+			fmt.Fprintln(stdout, "Unexpected: checksum accepted")
+			return 1
 		}
-		return
+		return 0
+	}
+
+	if *sortIn {
+		return sortLines(stdin, stdout, stderr, *strict)
+	}
+
+	if *parseOnly == "-" {
+		return parseLines(stdin, stdout, stderr, *asJSON, *field)
 	}
 
 	// Parse path: verifies checksum automatically if present.
 	if *parseOnly != "" {
 		p, err := oi.Parse(*parseOnly)
 		if err != nil {
-			log.Fatalf("parse error: %v", err)
+			fmt.Fprintf(stderr, "parse error: %v\n", err)
+			return 1
 		}
-		printParsed(*parseOnly, p)
-		return
+		switch {
+		case *field != "":
+			fmt.Fprintln(stdout, fieldValue(p, *field))
+			return 0
+		case *asJSON:
+			return writeJSON(stdout, stderr, *parseOnly, p)
+		}
+		printParsed(stdout, *parseOnly, p)
+		return 0
 	}
 
 	if *prefix == "" {
-		fmt.Fprintf(os.Stderr, "usage: %s -prefix <type> [-tenant N] [-shard N|-shard-from STR] [-checksum] [-bucket SEC] [-n N]\n", os.Args[0])
-		fmt.Fprintln(os.Stderr, "       or:  -parse <id>   (verify and inspect)")
-		fmt.Fprintln(os.Stderr, "       or:  -tamper <id>  (intentionally break checksum)")
-		os.Exit(2)
+		fmt.Fprintf(stderr, "usage: %s -prefix <type> [-tenant N] [-shard N|-shard-from STR] [-checksum] [-bucket SEC] [-time T] [-n N] [-json]\n", os.Args[0])
+		fmt.Fprintln(stderr, "       or:  -parse <id> [-json|-field F]  (verify and inspect)")
+		fmt.Fprintln(stderr, "       or:  -parse - [-json|-field F]     (validate one ID per line of stdin)")
+		fmt.Fprintln(stderr, "       or:  -sort [-strict]      (sort the IDs on stdin)")
+		fmt.Fprintln(stderr, "       or:  -tamper <id>  (intentionally break checksum)")
+		fmt.Fprintln(stderr, "With -json, each ID is printed as one JSON object per line (NDJSON).")
+		return 2
 	}
 
 	var opts []oi.Option
@@ -78,28 +123,258 @@ func main() {
 	if *bucket > 0 {
 		opts = append(opts, oi.WithBucketSeconds(*bucket))
 	}
+	if *at != "" {
+		t, err := parseTime(*at)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		// An explicit time is deliberate, so fixtures may lie in the future.
+		opts = append(opts, oi.WithTime(t), oi.WithAllowFuture())
+	}
 
 	for i := 0; i < *count; i++ {
-		id := oi.New(*prefix, opts...)
+		id, err := oi.NewE(*prefix, opts...)
+		if err != nil {
+			fmt.Fprintf(stderr, "generate error: %v\n", err)
+			return 1
+		}
 		p, err := oi.Parse(id) // sanity check (also verifies checksum if present)
 		if err != nil {
-			log.Fatalf("internal parse error: %v", err)
+			fmt.Fprintf(stderr, "internal parse error: %v\n", err)
+			return 1
+		}
+		if *asJSON {
+			if code := writeJSON(stdout, stderr, id, p); code != 0 {
+				return code
+			}
+			continue
 		}
-		fmt.Printf("%-34s  %s  tenant=%-5d shard=%-5d seq=%d\n",
+		fmt.Fprintf(stdout, "%-34s  %s  tenant=%-5d shard=%-5d seq=%d\n",
 			id,
-			time.UnixMilli(p.TimeMs).UTC().Format(time.RFC3339Nano),
+			p.Time().Format(time.RFC3339Nano),
 			p.Tenant, p.Shard, p.Seq)
 	}
+	return 0
+}
+
+// parseTime reads a -time value: an RFC3339 timestamp, or an integer number of
+// Unix milliseconds.
+func parseTime(s string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -time %q: want an RFC3339 timestamp or Unix milliseconds", s)
+	}
+	return t, nil
 }
 
-func printParsed(id string, p *oi.Parsed) {
-	fmt.Printf("ID:         %s\n", id)
-	fmt.Printf("prefix:     %s\n", p.Prefix)
-	fmt.Printf("time (ms):  %d\n", p.TimeMs)
-	fmt.Printf("time (iso): %s\n", time.UnixMilli(p.TimeMs).UTC().Format(time.RFC3339Nano))
-	fmt.Printf("flags:      0x%02x\n", p.Flags)
-	fmt.Printf("tenant:     %d\n", p.Tenant)
-	fmt.Printf("seq:        %d\n", p.Seq)
-	fmt.Printf("shard:      %d\n", p.Shard)
-	fmt.Printf("random60:   0x%016x\n", p.Random)
+// parseLines validates one ID per line of r, skipping blank lines, and prints
+// a result line for each. With field set, only that component of valid IDs
+// is printed and failures go to stderr. It returns 1 if any line fails to
+// parse.
+func parseLines(r io.Reader, stdout, stderr io.Writer, asJSON bool, field string) int {
+	code := 0
+	err := eachLine(r, func(_ int, line string, err error) bool {
+		var p *oi.Parsed
+		if err == nil {
+			p, err = oi.Parse(line)
+		}
+		if err != nil {
+			code = 1
+		}
+		switch {
+		case field != "" && err != nil:
+			fmt.Fprintf(stderr, "ERR %s: %v\n", line, err)
+		case field != "":
+			fmt.Fprintln(stdout, fieldValue(p, field))
+		case asJSON && err != nil:
+			if err := json.NewEncoder(stdout).Encode(failure{ID: line, Error: err.Error()}); err != nil {
+				fmt.Fprintf(stderr, "write error: %v\n", err)
+				code = 1
+				return false
+			}
+		case asJSON:
+			if c := writeJSON(stdout, stderr, line, p); c != 0 {
+				code = c
+				return false
+			}
+		case err != nil:
+			fmt.Fprintf(stdout, "ERR %s: %v\n", line, err)
+		default:
+			fmt.Fprintf(stdout, "OK  %s\n", line)
+		}
+		return true
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "read error: %v\n", err)
+		return 1
+	}
+	return code
+}
+
+// maxLine is the longest input line examined; longer lines are invalid.
+const maxLine = bufio.MaxScanTokenSize
+
+// errLongLine is reported for input lines longer than maxLine.
+var errLongLine = fmt.Errorf("line longer than %d bytes", maxLine)
+
+// eachLine calls fn with the number and trimmed text of each non-blank line
+// of r until fn returns false. Lines longer than maxLine are passed truncated
+// to maxLine with "..." appended, and with errLongLine. eachLine returns the
+// first error from reading r.
+func eachLine(r io.Reader, fn func(n int, line string, err error) bool) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		var (
+			buf  []byte
+			long bool
+		)
+		for {
+			frag, more, err := br.ReadLine()
+			if err == io.EOF && (len(buf) > 0 || long) {
+				break
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if room := maxLine - len(buf); len(frag) > room {
+				frag, long = frag[:room], true
+			}
+			buf = append(buf, frag...)
+			if !more {
+				break
+			}
+		}
+		line := strings.TrimSpace(string(buf))
+		var err error
+		if long {
+			line, err = line+"...", errLongLine
+		}
+		if line == "" {
+			continue
+		}
+		if !fn(n, line, err) {
+			return nil
+		}
+	}
+}
+
+// fieldNames lists the components accepted by -field.
+var fieldNames = []string{"prefix", "time", "tenant", "shard", "seq", "random", "flags"}
+
+// fieldValue formats one component of p for -field. Times are RFC3339 and
+// random is 16 hex digits, as in the -json output.
+func fieldValue(p *oi.Parsed, name string) string {
+	switch name {
+	case "prefix":
+		return p.Prefix
+	case "time":
+		return p.Time().Format(time.RFC3339Nano)
+	case "tenant":
+		return strconv.Itoa(int(p.Tenant))
+	case "shard":
+		return strconv.Itoa(int(p.Shard))
+	case "seq":
+		return strconv.Itoa(int(p.Seq))
+	case "random":
+		return p.RandomHex()
+	case "flags":
+		return strconv.Itoa(int(p.Flags))
+	}
+	panic("unknown field " + name)
+}
+
+// sortLines prints the IDs read one per line from r in Compare order. Invalid
+// lines are reported to stderr and skipped; if strict is set, nothing is
+// printed and sortLines returns 1 instead.
+func sortLines(r io.Reader, stdout, stderr io.Writer, strict bool) int {
+	var ids []string
+	bad := 0
+	err := eachLine(r, func(n int, line string, err error) bool {
+		if err == nil {
+			_, err = oi.Parse(line)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "line %d: %s: %v\n", n, line, err)
+			bad++
+			return true
+		}
+		ids = append(ids, line)
+		return true
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "read error: %v\n", err)
+		return 1
+	}
+	if strict && bad > 0 {
+		return 1
+	}
+	slices.SortStableFunc(ids, oi.Compare)
+	for _, id := range ids {
+		fmt.Fprintln(stdout, id)
+	}
+	return 0
+}
+
+func printParsed(w io.Writer, id string, p *oi.Parsed) {
+	fmt.Fprintf(w, "ID:         %s\n", id)
+	fmt.Fprintf(w, "prefix:     %s\n", p.Prefix)
+	fmt.Fprintf(w, "time (ms):  %d\n", p.TimeMs)
+	fmt.Fprintf(w, "time (iso): %s\n", p.Time().Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "flags:      0x%02x\n", p.Flags)
+	fmt.Fprintf(w, "tenant:     %d\n", p.Tenant)
+	fmt.Fprintf(w, "seq:        %d\n", p.Seq)
+	fmt.Fprintf(w, "shard:      %d\n", p.Shard)
+	fmt.Fprintf(w, "random60:   0x%016x\n", p.Random)
+}
+
+// record is the -json form of an ID. random60 is written as 16 hex digits,
+// like the random_hex field of the spec vectors, because 60-bit integers lose
+// precision in JSON tools that read numbers as doubles.
+type record struct {
+	ID       string `json:"id"`
+	Prefix   string `json:"prefix"`
+	TimeMs   int64  `json:"time_ms"`
+	TimeISO  string `json:"time_iso"`
+	Flags    uint8  `json:"flags"`
+	Tenant   uint16 `json:"tenant"`
+	Seq      uint16 `json:"seq"`
+	Shard    uint16 `json:"shard"`
+	Random60 string `json:"random60"`
+}
+
+// failure is the -json form of an input line that does not parse.
+type failure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+func newRecord(id string, p *oi.Parsed) record {
+	return record{
+		ID:       id,
+		Prefix:   p.Prefix,
+		TimeMs:   p.TimeMs,
+		TimeISO:  p.Time().Format(time.RFC3339Nano),
+		Flags:    p.Flags,
+		Tenant:   p.Tenant,
+		Seq:      p.Seq,
+		Shard:    p.Shard,
+		Random60: p.RandomHex(),
+	}
+}
+
+// writeJSON prints the record for id as one line of JSON and returns the exit
+// code.
+func writeJSON(stdout, stderr io.Writer, id string, p *oi.Parsed) int {
+	if err := json.NewEncoder(stdout).Encode(newRecord(id, p)); err != nil {
+		fmt.Fprintf(stderr, "write error: %v\n", err)
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	oi "github.com/orderlykit/orderlyid"
+)
+
+// runCmd runs the command with args and empty stdin and returns its exit
+// code and output.
+func runCmd(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	return runWithStdin(t, strings.NewReader(""), args...)
+}
+
+func runWithStdin(t *testing.T, stdin io.Reader, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, stdin, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// idFile writes lines to a temporary file and returns it opened for reading.
+func idFile(t *testing.T, lines ...string) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestGenerateJSON(t *testing.T) {
+	code, out, errOut := runCmd(t, "-prefix", "order", "-tenant", "7", "-checksum", "-n", "3", "-json")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	sc := bufio.NewScanner(strings.NewReader(out))
+	n := 0
+	for sc.Scan() {
+		n++
+		var r record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("line %d: %v", n, err)
+		}
+		p, err := oi.Parse(r.ID)
+		if err != nil {
+			t.Fatalf("line %d: %v", n, err)
+		}
+		if r.Prefix != "order" || r.Tenant != 7 || r.TimeMs != p.TimeMs || r.Random60 != p.RandomHex() {
+			t.Fatalf("line %d: record %+v does not match %+v", n, r, p)
+		}
+	}
+	if n != 3 {
+		t.Fatalf("got %d lines, want 3", n)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	id, err := oi.NewFromParts(oi.Components{
+		Prefix:   "user",
+		TimeMs:   1735689600123,
+		Flags:    0x02,
+		Tenant:   42,
+		Seq:      5,
+		Shard:    9,
+		Random60: 0xABCDEF,
+	}, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	code, out, errOut := runCmd(t, "-parse", id, "-json")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	want := map[string]any{
+		"id":       id,
+		"prefix":   "user",
+		"time_ms":  float64(1735689600123),
+		"time_iso": "2025-01-01T00:00:00.123Z",
+		"flags":    float64(2),
+		"tenant":   float64(42),
+		"seq":      float64(5),
+		"shard":    float64(9),
+		"random60": "0000000000abcdef",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("fields = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+
+	// The default output stays the human table.
+	if _, out, _ := runCmd(t, "-parse", id); !strings.HasPrefix(out, "ID:         "+id+"\n") {
+		t.Fatalf("human output changed:\n%s", out)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if code, _, errOut := runCmd(t, "-parse", "order_123", "-json"); code != 1 || !strings.Contains(errOut, "parse error") {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+}
+
+func TestParseStdin(t *testing.T) {
+	good := oi.New("order", oi.WithChecksum(true))
+	other := oi.New("user")
+	lines := []string{good, "", "  " + other + "  ", "order_123"}
+
+	code, out, errOut := runWithStdin(t, idFile(t, lines...), "-parse", "-")
+	if code != 1 {
+		t.Fatalf("exit %d, want 1; stderr %q", code, errOut)
+	}
+	got := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	want := []string{"OK  " + good, "OK  " + other, "ERR order_123: "}
+	if len(got) != len(want) {
+		t.Fatalf("output:\n%s", out)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Fatalf("line %d = %q, want prefix %q", i, got[i], want[i])
+		}
+	}
+
+	code, out, errOut = runWithStdin(t, idFile(t, lines...), "-parse", "-", "-json")
+	if code != 1 {
+		t.Fatalf("json: exit %d, want 1; stderr %q", code, errOut)
+	}
+	dec := json.NewDecoder(strings.NewReader(out))
+	for i, id := range []string{good, other, "order_123"} {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if m["id"] != id {
+			t.Fatalf("record %d id = %v, want %s", i, m["id"], id)
+		}
+		if _, failed := m["error"]; failed != (i == 2) {
+			t.Fatalf("record %d = %v", i, m)
+		}
+	}
+
+	if code, _, errOut := runWithStdin(t, idFile(t, good, other), "-parse", "-"); code != 0 {
+		t.Fatalf("all valid: exit %d; stderr %q", code, errOut)
+	}
+}
+
+func TestGenerateAtTime(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "rfc3339", args: []string{"-time", "2025-01-01T00:00:07.25Z"}, want: "2025-01-01T00:00:07.25Z"},
+		{name: "offset", args: []string{"-time", "2025-01-01T02:00:07+02:00"}, want: "2025-01-01T00:00:07Z"},
+		{name: "unix ms", args: []string{"-time", "1735689607250"}, want: "2025-01-01T00:00:07.25Z"},
+		{name: "bucketed", args: []string{"-time", "2025-01-01T00:00:07.25Z", "-bucket", "5"}, want: "2025-01-01T00:00:05Z"},
+		{name: "future", args: []string{"-time", "2999-12-31T23:59:59Z"}, want: "2999-12-31T23:59:59Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out, errOut := runCmd(t, append([]string{"-prefix", "order", "-json"}, tt.args...)...)
+			if code != 0 {
+				t.Fatalf("exit %d: %s", code, errOut)
+			}
+			var r record
+			if err := json.Unmarshal([]byte(out), &r); err != nil {
+				t.Fatalf("unmarshal %q: %v", out, err)
+			}
+			if r.TimeISO != tt.want {
+				t.Fatalf("time_iso = %s, want %s", r.TimeISO, tt.want)
+			}
+		})
+	}
+
+	code, _, errOut := runCmd(t, "-prefix", "order", "-time", "yesterday")
+	if code != 2 || !strings.Contains(errOut, `invalid -time "yesterday"`) {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+}
+
+func TestSortStdin(t *testing.T) {
+	var want []string
+	for i := 0; i < 20; i++ {
+		id, err := oi.NewFromParts(oi.Components{
+			Prefix:   "order",
+			TimeMs:   1735689600000 + int64(i),
+			Random60: uint64(20 - i),
+		}, i%3 == 0)
+		if err != nil {
+			t.Fatalf("NewFromParts: %v", err)
+		}
+		want = append(want, id)
+	}
+	in := slices.Clone(want)
+	rand.New(rand.NewSource(1)).Shuffle(len(in), func(i, j int) { in[i], in[j] = in[j], in[i] })
+
+	code, out, errOut := runWithStdin(t, idFile(t, in...), "-sort")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if got := strings.Fields(out); !slices.Equal(got, want) {
+		t.Fatalf("sorted:\n%s\nwant:\n%s", out, strings.Join(want, "\n"))
+	}
+
+	withBad := append(slices.Clone(in), "order_123")
+	code, out, errOut = runWithStdin(t, idFile(t, withBad...), "-sort")
+	if code != 0 || !strings.Contains(errOut, "order_123") {
+		t.Fatalf("lenient: exit %d, stderr %q", code, errOut)
+	}
+	if got := strings.Fields(out); !slices.Equal(got, want) {
+		t.Fatalf("lenient output:\n%s", out)
+	}
+
+	code, out, errOut = runWithStdin(t, idFile(t, withBad...), "-sort", "-strict")
+	if code != 1 || out != "" || !strings.Contains(errOut, "line 21: order_123") {
+		t.Fatalf("strict: exit %d, stdout %q, stderr %q", code, out, errOut)
+	}
+}
+
+func TestParseField(t *testing.T) {
+	id, err := oi.NewFromParts(oi.Components{
+		Prefix:   "user",
+		TimeMs:   1735689600123,
+		Flags:    0x02,
+		Tenant:   42,
+		Seq:      5,
+		Shard:    9,
+		Random60: 0xABCDEF,
+	}, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	want := map[string]string{
+		"prefix": "user",
+		"time":   "2025-01-01T00:00:00.123Z",
+		"tenant": "42",
+		"shard":  "9",
+		"seq":    "5",
+		"random": "0000000000abcdef",
+		"flags":  "2",
+	}
+	if len(want) != len(fieldNames) {
+		t.Fatalf("test covers %d fields, command has %d", len(want), len(fieldNames))
+	}
+	for _, name := range fieldNames {
+		t.Run(name, func(t *testing.T) {
+			code, out, errOut := runCmd(t, "-parse", id, "-field", name)
+			if code != 0 {
+				t.Fatalf("exit %d: %s", code, errOut)
+			}
+			if out != want[name]+"\n" {
+				t.Fatalf("output = %q, want %q", out, want[name])
+			}
+		})
+	}
+
+	code, out, errOut := runWithStdin(t, idFile(t, id, "order_123", id), "-parse", "-", "-field", "tenant")
+	if code != 1 || out != "42\n42\n" || !strings.Contains(errOut, "ERR order_123") {
+		t.Fatalf("stdin: exit %d, stdout %q, stderr %q", code, out, errOut)
+	}
+
+	if code, _, errOut := runCmd(t, "-parse", id, "-field", "region"); code != 2 || !strings.Contains(errOut, `unknown -field "region"`) {
+		t.Fatalf("unknown field: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, _ := runCmd(t, "-parse", id, "-field", "seq", "-json"); code != 2 {
+		t.Fatalf("-field with -json: exit %d, want 2", code)
+	}
+}
+
+func TestLongLines(t *testing.T) {
+	id := oi.New("order")
+	in := id + "\n" + strings.Repeat("x", 100<<10) + "\n" + id + "\n"
+
+	code, out, errOut := runWithStdin(t, strings.NewReader(in), "-parse", "-")
+	if code != 1 || strings.Count(out, "OK  "+id) != 2 || !strings.Contains(out, "line longer than") {
+		t.Fatalf("parse: exit %d, stdout %.200q, stderr %q", code, out, errOut)
+	}
+
+	code, out, errOut = runWithStdin(t, strings.NewReader(in), "-sort")
+	if code != 0 || strings.Count(out, id) != 2 || !strings.Contains(errOut, "line 2: ") || !strings.Contains(errOut, "line longer than") {
+		t.Fatalf("sort: exit %d, stdout %q, stderr %.200q", code, out, errOut)
+	}
+}
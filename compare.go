@@ -0,0 +1,174 @@
+package orderlyid
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Sort sorts ids in place into the order defined by Compare, which is
+// chronological for IDs with the same prefix. IDs that cannot be parsed are
+// placed after all valid ones.
+func Sort(ids []string) {
+	sortIDs(ids, slices.SortFunc)
+}
+
+// SortStable is like Sort but keeps equal IDs (for example the same ID with
+// and without a checksum) in their original relative order.
+func SortStable(ids []string) {
+	sortIDs(ids, slices.SortStableFunc)
+}
+
+// sortIDs sorts ids by Compare with sortFunc, parsing each ID only once.
+func sortIDs(ids []string, sortFunc func([]sortEntry, func(a, b sortEntry) int)) {
+	entries := make([]sortEntry, len(ids))
+	for i, id := range ids {
+		p, _ := Parse(id)
+		entries[i] = sortEntry{id, p}
+	}
+	sortFunc(entries, func(a, b sortEntry) int {
+		return compareOrInvalid(a.id, b.id, a.p, b.p)
+	})
+	for i, e := range entries {
+		ids[i] = e.id
+	}
+}
+
+type sortEntry struct {
+	id string
+	p  *Parsed // nil if id does not parse
+}
+
+// Compare compares two IDs by their decoded ordering. IDs with different
+// prefixes are ordered lexically by prefix; IDs with the same prefix are
+// ordered by time first, then sequence, then flags, tenant, shard, and random.
+// It returns -1 if a sorts before b, +1 if after, and 0 if both decode to the
+// same ID. Compare and Less can be passed to slices.SortFunc and sort.Slice.
+//
+// An optional checksum suffix is ignored, so a list mixing IDs with and
+// without checksums sorts the same as the list without them. IDs that cannot
+// be parsed sort after every valid ID, and among themselves by their
+// case-folded "prefix_payload" text, so Compare is a total order on all
+// strings.
+func Compare(a, b string) int {
+	pa, _ := Parse(a)
+	pb, _ := Parse(b)
+	return compareOrInvalid(a, b, pa, pb)
+}
+
+// compareOrInvalid compares a and b, given their parsed forms pa and pb or nil
+// for IDs that do not parse.
+func compareOrInvalid(a, b string, pa, pb *Parsed) int {
+	switch {
+	case pa != nil && pb != nil:
+		return compareParsed(pa, pb)
+	case pa != nil:
+		return -1
+	case pb != nil:
+		return 1
+	}
+	return compareBase(a, b)
+}
+
+// Less reports whether a sorts before b under Compare.
+func Less(a, b string) bool {
+	return Compare(a, b) < 0
+}
+
+// Equal reports whether a and b decode to the same ID, regardless of whether
+// either carries a checksum suffix or uses uppercase payload characters.
+//
+// Equal returns the Parse error of the first ID that cannot be parsed.
+func Equal(a, b string) (bool, error) {
+	pa, err := Parse(a)
+	if err != nil {
+		return false, err
+	}
+	pb, err := Parse(b)
+	if err != nil {
+		return false, err
+	}
+	return compareParsed(pa, pb) == 0, nil
+}
+
+// Between reports whether id sorts within the inclusive range [lo, hi] under
+// the decoded ordering used by Compare. Checksum suffixes are ignored.
+//
+// Between returns the Parse error of the first argument that cannot be parsed.
+func Between(id, lo, hi string) (bool, error) {
+	p, err := Parse(id)
+	if err != nil {
+		return false, err
+	}
+	pl, err := Parse(lo)
+	if err != nil {
+		return false, err
+	}
+	ph, err := Parse(hi)
+	if err != nil {
+		return false, err
+	}
+	return compareParsed(pl, p) <= 0 && compareParsed(p, ph) <= 0, nil
+}
+
+func compareParsed(a, b *Parsed) int {
+	if c := strings.Compare(a.Prefix, b.Prefix); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.TimeMs, b.TimeMs); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Seq, b.Seq); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Flags, b.Flags); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Tenant, b.Tenant); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Shard, b.Shard); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Random, b.Random)
+}
+
+// baseOf returns s without surrounding whitespace and without a trailing
+// "-checksum" suffix.
+func baseOf(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// compareBase compares the "prefix_payload" portions of a and b byte-wise,
+// folding ASCII letters to lowercase.
+func compareBase(a, b string) int {
+	a, b = baseOf(a), baseOf(b)
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		ca, cb := lower(a[i]), lower(b[i])
+		if ca != cb {
+			if ca < cb {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,275 @@
+package orderlyid
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chronoIDs returns n IDs minted one millisecond apart, alternating between
+// checksummed and plain forms.
+func chronoIDs(t *testing.T, n int) []string {
+	t.Helper()
+	ids := make([]string, n)
+	for i := range ids {
+		id, err := NewFromParts(Components{
+			Prefix:   "order",
+			TimeMs:   1735689600000 + int64(i),
+			Random60: uint64(n - i),
+		}, i%2 == 0)
+		if err != nil {
+			t.Fatalf("NewFromParts: %v", err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func TestSortChronological(t *testing.T) {
+	want := chronoIDs(t, 64)
+	got := slices.Clone(want)
+	r := rand.New(rand.NewSource(1))
+	r.Shuffle(len(got), func(i, j int) { got[i], got[j] = got[j], got[i] })
+
+	Sort(got)
+	if !slices.Equal(got, want) {
+		t.Fatalf("Sort mismatch:\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestSortStableKeepsChecksumVariantsInOrder(t *testing.T) {
+	ids := chronoIDs(t, 3)
+	plain := baseOf(ids[0])
+	in := []string{ids[2], ids[0], ids[1], plain}
+
+	SortStable(in)
+	want := []string{ids[0], plain, ids[1], ids[2]}
+	if !slices.Equal(in, want) {
+		t.Fatalf("SortStable mismatch:\n got: %v\nwant: %v", in, want)
+	}
+}
+
+func TestSortAgreesWithCompare(t *testing.T) {
+	mk := func(prefix string, tenant, seq uint16) string {
+		id, err := NewFromParts(Components{
+			Prefix: prefix,
+			TimeMs: 1735689600000,
+			Tenant: tenant,
+			Seq:    seq,
+		}, seq%2 == 0)
+		if err != nil {
+			t.Fatalf("NewFromParts: %v", err)
+		}
+		return id
+	}
+	ids := []string{
+		"not an id",
+		mk("a10", 0, 0),
+		mk("a1", 2, 1),
+		mk("a1", 1, 2),
+		"order_zzz",
+		mk("a1", 0, 3),
+		"",
+	}
+	for _, sortFn := range []func([]string){Sort, SortStable} {
+		got := slices.Clone(ids)
+		sortFn(got)
+		if !sort.SliceIsSorted(got, func(i, j int) bool { return Less(got[i], got[j]) }) {
+			t.Fatalf("sorted list not ordered by Compare: %q", got)
+		}
+		// The prefix a1 sorts before a10; within a millisecond the sequence
+		// outranks the tenant; unparseable inputs go last.
+		want := []string{mk("a1", 2, 1), mk("a1", 1, 2), mk("a1", 0, 3), mk("a10", 0, 0), "", "not an id", "order_zzz"}
+		if !slices.Equal(got, want) {
+			t.Fatalf("sorted:\n got: %q\nwant: %q", got, want)
+		}
+	}
+}
+
+func TestCompareInvalidIsTransitive(t *testing.T) {
+	valid := chronoIDs(t, 2)
+	for _, bad := range []string{"", "a_0", "zzzz"} {
+		for _, id := range valid {
+			if Compare(id, bad) != -1 || Compare(bad, id) != 1 {
+				t.Fatalf("Compare(%q, %q) does not place the invalid ID last", id, bad)
+			}
+		}
+	}
+}
+
+func TestCompareSameTimeDifferentSeq(t *testing.T) {
+	mk := func(seq uint16, random uint64, checksum bool) string {
+		id, err := NewFromParts(Components{
+			Prefix:   "order",
+			TimeMs:   1735689600000,
+			Seq:      seq,
+			Random60: random,
+		}, checksum)
+		if err != nil {
+			t.Fatalf("NewFromParts: %v", err)
+		}
+		return id
+	}
+
+	// A higher random value must not outrank a lower sequence number.
+	lo := mk(1, 1<<59, true)
+	hi := mk(2, 0, false)
+	if got := Compare(lo, hi); got != -1 {
+		t.Fatalf("Compare(seq1, seq2) = %d, want -1", got)
+	}
+	if got := Compare(hi, lo); got != 1 {
+		t.Fatalf("Compare(seq2, seq1) = %d, want 1", got)
+	}
+	if !Less(lo, hi) || Less(hi, lo) {
+		t.Fatalf("Less disagrees with sequence order")
+	}
+	if got := Compare(lo, baseOf(lo)); got != 0 {
+		t.Fatalf("Compare with and without checksum = %d, want 0", got)
+	}
+}
+
+func TestCompareIgnoresChecksumInMixedLists(t *testing.T) {
+	var plain []string
+	for _, prefix := range []string{"user", "order", "a10", "a1"} {
+		for i := 0; i < 4; i++ {
+			id, err := NewFromParts(Components{
+				Prefix:   prefix,
+				TimeMs:   1735689600000 + int64(3-i),
+				Random60: uint64(i),
+			}, false)
+			if err != nil {
+				t.Fatalf("NewFromParts: %v", err)
+			}
+			plain = append(plain, id)
+		}
+	}
+	mixed := make([]string, len(plain))
+	for i, id := range plain {
+		mixed[i] = id
+		if i%2 == 1 {
+			mixed[i] = id + "-" + checksum4Base(id)
+		}
+	}
+
+	slices.SortFunc(plain, Compare)
+	sort.Slice(mixed, func(i, j int) bool { return Less(mixed[i], mixed[j]) })
+	for i := range plain {
+		if baseOf(mixed[i]) != plain[i] {
+			t.Fatalf("position %d: mixed %s, plain %s", i, mixed[i], plain[i])
+		}
+	}
+	// Different prefixes order lexically by prefix, whatever their times.
+	for i, want := range []string{"a1", "a10", "order", "user"} {
+		if p := mustParse(t, plain[4*i]); p.Prefix != want {
+			t.Fatalf("group %d prefix = %s, want %s", i, p.Prefix, want)
+		}
+	}
+}
+
+func TestCompareFallsBackForInvalidIDs(t *testing.T) {
+	if got := Compare("order_a", "order_b"); got != -1 {
+		t.Fatalf("Compare(invalid) = %d, want -1", got)
+	}
+}
+
+func TestEqualIgnoresChecksum(t *testing.T) {
+	ids := chronoIDs(t, 2)
+	withCS := ids[0]
+	plain := baseOf(withCS)
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "checksum vs plain", a: withCS, b: plain, want: true},
+		{name: "uppercase payload", a: plain, b: "order_" + strings.ToUpper(plain[len("order_"):]), want: true},
+		{name: "different ids", a: withCS, b: ids[1], want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Equal(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Equal: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Equal(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Equal(plain, "order_123"); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+}
+
+func TestBetweenBoundaries(t *testing.T) {
+	ids := chronoIDs(t, 5)
+	lo, hi := ids[1], ids[3]
+
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "before", id: ids[0], want: false},
+		{name: "lower bound", id: lo, want: true},
+		{name: "lower bound without checksum", id: baseOf(lo), want: true},
+		{name: "inside", id: ids[2], want: true},
+		{name: "upper bound", id: hi, want: true},
+		{name: "after", id: ids[4], want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Between(tt.id, lo, hi)
+			if err != nil {
+				t.Fatalf("Between: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Between(%s) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Between(ids[2], "order", hi); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestIDForTimeBounds(t *testing.T) {
+	at := time.UnixMilli(1735689600123)
+	lo, hi := MinIDForTime("order", at), MaxIDForTime("order", at)
+	if lo >= hi {
+		t.Fatalf("MinIDForTime %s not below MaxIDForTime %s", lo, hi)
+	}
+	g := NewGenerator()
+	for i := 0; i < 500; i++ {
+		id := g.New("order",
+			WithTime(at),
+			WithTenant(uint16(i*131)),
+			WithShard(uint16(i*257)),
+			WithRegion(uint8(i%16)),
+			WithChecksum(i%2 == 0),
+		)
+		if base := baseOf(id); base < lo || base > hi {
+			t.Fatalf("%s outside [%s, %s]", id, lo, hi)
+		}
+		if in, err := Between(id, lo, hi); err != nil || !in {
+			t.Fatalf("Between(%s) = %v, %v", id, in, err)
+		}
+	}
+
+	// Neighbouring milliseconds fall outside the bounds.
+	before := MaxIDForTime("order", at.Add(-time.Millisecond))
+	after := MinIDForTime("order", at.Add(time.Millisecond))
+	if before >= lo || after <= hi {
+		t.Fatalf("neighbouring bounds overlap: %s %s %s %s", before, lo, hi, after)
+	}
+	if p := mustParse(t, lo); p.TimeMs != at.UnixMilli() || p.Random != 0 {
+		t.Fatalf("MinIDForTime decodes to %+v", p)
+	}
+}
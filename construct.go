@@ -3,6 +3,7 @@ package orderlyid
 import (
 	"encoding/hex"
 	"fmt"
+	"time"
 )
 
 // Components describes the public fields packed into an OrderlyID.
@@ -57,16 +58,97 @@ func NewFromParts(c Components, withChecksum bool) (string, error) {
 // NewFromPartsHex may return an error wrapping ErrInvalidPrefix or
 // ErrInvalidRandomHex.
 func NewFromPartsHex(c Components, randomHex string, withChecksum bool) (string, error) {
-	rb, err := hex.DecodeString(randomHex)
+	r, err := random60FromHex(randomHex)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInvalidRandomHex, err)
+		return "", err
+	}
+	c.Random60 = r
+	return NewFromParts(c, withChecksum)
+}
+
+// RandomHex returns Random60 as 16 big-endian hex digits, the form used by
+// the random_hex field of the spec test vectors. Bits above the low 60 are
+// dropped.
+func (c Components) RandomHex() string {
+	return random60Hex(c.Random60)
+}
+
+func random60Hex(r uint64) string {
+	return fmt.Sprintf("%016x", r&((1<<60)-1))
+}
+
+// random60FromHex decodes big-endian hex and keeps the low 60 bits.
+func random60FromHex(h string) (uint64, error) {
+	rb, err := hex.DecodeString(h)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRandomHex, err)
 	}
 	var u uint64
 	for _, b := range rb {
 		u = (u << 8) | uint64(b)
 	}
-	c.Random60 = u & ((1 << 60) - 1)
-	return NewFromParts(c, withChecksum)
+	return u & ((1 << 60) - 1), nil
+}
+
+// FromBytes builds an OrderlyID from a packed 20-byte body, such as one
+// returned by Parsed.Bytes.
+//
+// FromBytes may return an error wrapping ErrInvalidPrefix.
+func FromBytes(prefix string, body [20]byte, withChecksum bool) (string, error) {
+	if err := validatePrefix(prefix); err != nil {
+		return "", err
+	}
+	base := prefix + "_" + b32encode(body[:])
+	if withChecksum {
+		return base + "-" + checksum4Base(base), nil
+	}
+	return base, nil
+}
+
+// formatBody renders body with prefix, which must be valid, and an
+// n-character checksum, or none if n is 0.
+func formatBody(prefix string, body [20]byte, n int) string {
+	o := options{withChecksum: n > 0, checksumLen: n}
+	return o.format(prefix, body)
+}
+
+// MinIDForTime returns the smallest ID with prefix at the millisecond of t:
+// every field after the timestamp, including the random bits, is zero. Together
+// with MaxIDForTime it brackets every ID generated for prefix in that
+// millisecond, for range scans over a datastore keyed by ID.
+//
+// The bounds carry no checksum and use lowercase payloads. They bracket other
+// IDs under Compare, or byte-wise once those IDs are reduced to their
+// lowercase "prefix_payload" form; a stored checksum suffix sorts an ID just
+// past a bound that equals its base. Times before 2020-01-01 UTC clamp to the
+// epoch as in NewFromParts. MinIDForTime panics if prefix is invalid.
+func MinIDForTime(prefix string, t time.Time) string {
+	return mustBound(Components{Prefix: prefix, TimeMs: t.UnixMilli()})
+}
+
+// MaxIDForTime returns the largest ID with prefix at the millisecond of t:
+// tenant, sequence, shard, and random bits are all ones, and the flags byte
+// has every bit set except those of wire versions above MaxVersion, so the
+// bound still parses. See MinIDForTime for how the bounds compare with
+// stored IDs. MaxIDForTime panics if prefix is invalid.
+func MaxIDForTime(prefix string, t time.Time) string {
+	return mustBound(Components{
+		Prefix:   prefix,
+		TimeMs:   t.UnixMilli(),
+		Flags:    MaxVersion<<6 | ^FlagVersionMask,
+		Tenant:   0xFFFF,
+		Seq:      0x0FFF,
+		Shard:    0xFFFF,
+		Random60: 1<<60 - 1,
+	})
+}
+
+func mustBound(c Components) string {
+	id, err := NewFromParts(c, false)
+	if err != nil {
+		panic(err)
+	}
+	return id
 }
 
 // validatePrefix mirrors your existing prefix regex check.
@@ -76,3 +158,32 @@ func validatePrefix(p string) error {
 	}
 	return nil
 }
+
+// checkPrefix validates p against the prefix rule and any prefix constraints
+// carried by o.
+func (o *options) checkPrefix(p string) error {
+	if err := validatePrefix(p); err != nil {
+		return err
+	}
+	return o.checkPrefixRules(p)
+}
+
+// checkPrefixRules applies the prefix constraints carried by o to p, which
+// must already satisfy the prefix rule.
+func (o *options) checkPrefixRules(p string) error {
+	if o.minPrefixLen > 0 && len(p) < o.minPrefixLen {
+		return fmt.Errorf("%w: %q is shorter than %d chars", ErrInvalidPrefix, p, o.minPrefixLen)
+	}
+	if o.maxPrefixLen > 0 && len(p) > o.maxPrefixLen {
+		return fmt.Errorf("%w: %q is longer than %d chars", ErrInvalidPrefix, p, o.maxPrefixLen)
+	}
+	if o.requireRegistered && !isRegistered(p) {
+		return fmt.Errorf("%w: %q", ErrUnregisteredPrefix, p)
+	}
+	if o.confusableCheck {
+		if r := confusableWith(p); r != "" {
+			return fmt.Errorf("%w: %q looks like registered prefix %q", ErrConfusablePrefix, p, r)
+		}
+	}
+	return nil
+}
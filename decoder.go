@@ -0,0 +1,30 @@
+package orderlyid
+
+// Decoder parses OrderlyIDs with a fixed validation configuration. Construct
+// one at startup with NewDecoder and reuse it: Decode does not allocate when
+// the ID is valid.
+//
+// A Decoder is safe for concurrent use.
+type Decoder struct {
+	o options
+}
+
+// NewDecoder returns a Decoder that applies the validation options in opts,
+// such as WithStrict, WithExpectedPrefix, WithTimeBounds, and the prefix
+// constraints accepted by ParseWith. Generation options are ignored.
+//
+// NewDecoder returns an error wrapping ErrInvalidOption if opts conflict.
+func NewDecoder(opts ...Option) (*Decoder, error) {
+	o := buildOptions(opts)
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return &Decoder{o: o}, nil
+}
+
+// Decode parses s into dst. dst is only modified if Decode succeeds.
+//
+// Decode may return any error returned by ParseWith.
+func (d *Decoder) Decode(s string, dst *Parsed) error {
+	return d.o.parse(s, dst)
+}
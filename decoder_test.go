@@ -0,0 +1,135 @@
+package orderlyid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoderOptions(t *testing.T) {
+	c := Components{Prefix: "order", TimeMs: 1735689600000, Tenant: 3, Random60: 0x1234}
+	id, err := NewFromParts(c, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	at := time.UnixMilli(c.TimeMs)
+
+	tests := []struct {
+		name string
+		opts []Option
+		in   string
+		want error
+	}{
+		{name: "default", in: id},
+		{name: "default uppercase", in: "order_" + strings.ToUpper(id[len("order_"):])},
+		{name: "strict canonical", opts: []Option{WithStrict()}, in: id},
+		{name: "strict uppercase", opts: []Option{WithStrict()}, in: "order_" + strings.ToUpper(id[len("order_"):]), want: ErrInvalidBase32},
+		{name: "strict whitespace", opts: []Option{WithStrict()}, in: " " + id, want: ErrInvalidFormat},
+		// The payload of a 2025 timestamp starts with "0", so "O" is its alias.
+		{name: "strict alias", opts: []Option{WithStrict()}, in: "order_O" + id[len("order_0"):], want: ErrInvalidBase32},
+		{name: "expected prefix", opts: []Option{WithExpectedPrefix("order")}, in: id},
+		{name: "unexpected prefix", opts: []Option{WithExpectedPrefix("user")}, in: id, want: ErrInvalidPrefix},
+		{name: "within bounds", opts: []Option{WithTimeBounds(at, at)}, in: id},
+		{name: "before bounds", opts: []Option{WithTimeBounds(at.Add(time.Millisecond), time.Time{})}, in: id, want: ErrTimeOutOfRange},
+		{name: "after bounds", opts: []Option{WithTimeBounds(time.Time{}, at.Add(-time.Millisecond))}, in: id, want: ErrTimeOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Parsed
+			d, err := NewDecoder(tt.opts...)
+			if err != nil {
+				t.Fatalf("NewDecoder: %v", err)
+			}
+			err = d.Decode(tt.in, &p)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Decode error = %v, want %v", err, tt.want)
+			}
+			if _, perr := ParseWith(tt.in, tt.opts...); !errors.Is(perr, tt.want) {
+				t.Fatalf("ParseWith error = %v, want %v", perr, tt.want)
+			}
+			if tt.want == nil && (p.Tenant != 3 || p.Random != 0x1234) {
+				t.Fatalf("unexpected result: %+v", p)
+			}
+			if tt.want != nil && p != (Parsed{}) {
+				t.Fatalf("dst modified on error: %+v", p)
+			}
+		})
+	}
+}
+
+func TestParseIntoMatchesParse(t *testing.T) {
+	id := New("order", WithTenant(9), WithChecksum(true))
+	want, err := Parse(id)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var got Parsed
+	if err := ParseInto(id, &got); err != nil {
+		t.Fatalf("ParseInto: %v", err)
+	}
+	if got != *want {
+		t.Fatalf("ParseInto = %+v, want %+v", got, *want)
+	}
+}
+
+func TestDecoderZeroAllocs(t *testing.T) {
+	id := New("order", WithChecksum(true))
+	d, err := NewDecoder(WithStrict(), WithExpectedPrefix("order"))
+	if err != nil {
+		t.Fatalf("NewDecoder: %v", err)
+	}
+	var p Parsed
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := d.Decode(id, &p); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("Decode allocated %v times per run, want 0", allocs)
+	}
+	allocs = testing.AllocsPerRun(100, func() {
+		if err := ParseInto(id, &p); err != nil {
+			t.Fatalf("ParseInto: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("ParseInto allocated %v times per run, want 0", allocs)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	id := New("order", WithChecksum(true))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseInto(b *testing.B) {
+	id := New("order")
+	var p Parsed
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := ParseInto(id, &p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoderDecode(b *testing.B) {
+	id := New("order", WithChecksum(true))
+	d, err := NewDecoder(WithExpectedPrefix("order"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var p Parsed
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := d.Decode(id, &p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -10,7 +10,8 @@
 //   - timestamp: 48-bit milliseconds since 2020-01-01T00:00:00Z, which makes IDs
 //     approximately ordered by creation time
 //   - flags: an 8-bit field where bits 7..6 carry the wire version, bit 5 marks
-//     privacy bucketing, and bits 4..0 are reserved
+//     privacy bucketing, bit 4 marks a region stored in the top 4 shard bits,
+//     and bits 3..0 are free for application use (see WithFlags)
 //   - tenant: a 16-bit tenant identifier for multi-tenant systems
 //   - sequence: a 12-bit counter for bursts within the same millisecond
 //   - shard: a 16-bit routing hint, either provided directly or derived from
@@ -20,5 +21,6 @@
 // Canonical output from this package is lowercase. Parsing is
 // case-insensitive for payload and checksum characters, but prefixes must still
 // satisfy the lowercase prefix rule. A trailing 4-character checksum is
-// optional and can be used to detect copy/paste and transcription errors.
+// optional and can be used to detect copy/paste and transcription errors;
+// WithChecksumLen selects a stronger 6- or 8-character checksum.
 package orderlyid
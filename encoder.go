@@ -0,0 +1,56 @@
+package orderlyid
+
+// Encoder renders Components as OrderlyIDs with a fixed configuration and a
+// reusable scratch buffer. Construct one with NewEncoder and reuse it for many
+// encodes to avoid passing options and reallocating on each call.
+//
+// An Encoder is not safe for concurrent use; give each goroutine its own.
+type Encoder struct {
+	o   options
+	buf []byte
+}
+
+// NewEncoder returns an Encoder configured by opts. The output options
+// WithChecksum, WithUppercaseOutput, and WithGroupingDashes apply, as do the
+// prefix constraints WithMinPrefixLength, WithMaxPrefixLength, and
+// WithRequireRegistered. Field options such as WithTenant are ignored because
+// Components carries every field.
+//
+// NewEncoder returns an error wrapping ErrInvalidOption if opts conflict.
+func NewEncoder(opts ...Option) (*Encoder, error) {
+	o := buildOptions(opts)
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return &Encoder{o: o}, nil
+}
+
+// Encode returns the ID for c. It packs c the same way as NewFromParts:
+// times before 2020-01-01 are clamped to the epoch, and Seq and Random60 are
+// masked to 12 and 60 bits.
+//
+// Encode may return an error wrapping ErrInvalidPrefix or
+// ErrUnregisteredPrefix.
+func (e *Encoder) Encode(c Components) (string, error) {
+	var err error
+	e.buf, err = e.AppendEncode(e.buf[:0], c)
+	if err != nil {
+		return "", err
+	}
+	return string(e.buf), nil
+}
+
+// AppendEncode appends the ID for c to dst and returns the extended buffer.
+// It returns dst unchanged together with the error if c is rejected; see
+// Encode.
+func (e *Encoder) AppendEncode(dst []byte, c Components) ([]byte, error) {
+	if err := e.o.checkPrefix(c.Prefix); err != nil {
+		return dst, err
+	}
+	var ms uint64
+	if c.TimeMs >= epoch2020 {
+		ms = uint64(c.TimeMs - epoch2020)
+	}
+	body := pack(ms, c.Flags, c.Tenant, c.Seq&0x0FFF, c.Shard, c.Random60&((1<<60)-1))
+	return e.o.appendFormat(dst, c.Prefix, &body), nil
+}
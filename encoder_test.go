@@ -0,0 +1,112 @@
+package orderlyid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustEncoder(t *testing.T, opts ...Option) *Encoder {
+	t.Helper()
+	enc, err := NewEncoder(opts...)
+	if err != nil {
+		t.Fatalf("NewEncoder: %v", err)
+	}
+	return enc
+}
+
+func TestEncoderMatchesNewFromParts(t *testing.T) {
+	for _, checksum := range []bool{false, true} {
+		enc := mustEncoder(t, WithChecksum(checksum))
+		var buf []byte
+		for i := 0; i < 100; i++ {
+			c := Components{
+				Prefix:   "order",
+				TimeMs:   1735689600000 + int64(i),
+				Flags:    uint8(i),
+				Tenant:   uint16(i * 7),
+				Seq:      uint16(i * 31),
+				Shard:    uint16(i * 13),
+				Random60: uint64(i) * 0x9E3779B97F4A7C15,
+			}
+			want, err := NewFromParts(c, checksum)
+			if err != nil {
+				t.Fatalf("NewFromParts: %v", err)
+			}
+			got, err := enc.Encode(c)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if got != want {
+				t.Fatalf("Encode = %s, want %s", got, want)
+			}
+			buf, err = enc.AppendEncode(buf[:0], c)
+			if err != nil {
+				t.Fatalf("AppendEncode: %v", err)
+			}
+			if string(buf) != want {
+				t.Fatalf("AppendEncode = %s, want %s", buf, want)
+			}
+		}
+	}
+}
+
+func TestEncoderDisplayOptions(t *testing.T) {
+	c := Components{Prefix: "order", TimeMs: 1735689600000, Random60: 42}
+	plain, err := NewFromParts(c, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	got, err := mustEncoder(t, WithChecksum(true), WithUppercaseOutput(), WithGroupingDashes(4)).Encode(c)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	canonical, _, err := ParseGrouped(got)
+	if err != nil {
+		t.Fatalf("ParseGrouped(%s): %v", got, err)
+	}
+	if !strings.EqualFold(canonical, plain) {
+		t.Fatalf("Encode = %s, want a display form of %s", got, plain)
+	}
+}
+
+func TestEncoderRejectsPrefix(t *testing.T) {
+	enc := mustEncoder(t, WithMaxPrefixLength(4))
+	dst := []byte("keep")
+	dst, err := enc.AppendEncode(dst, Components{Prefix: "order"})
+	if !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+	if string(dst) != "keep" {
+		t.Fatalf("dst modified on error: %q", dst)
+	}
+	if _, err := enc.Encode(Components{Prefix: "Bad!"}); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+}
+
+var benchComponents = Components{Prefix: "order", TimeMs: 1735689600000, Tenant: 1, Shard: 2, Random60: 0xABCDEF}
+
+func BenchmarkNewFromParts(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewFromParts(benchComponents, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncoderAppendEncode(b *testing.B) {
+	enc, err := NewEncoder()
+	if err != nil {
+		b.Fatal(err)
+	}
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = enc.AppendEncode(buf[:0], benchComponents); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package orderlyid
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	// seqBits is the width of the per-millisecond sequence field.
+	seqBits = 12
+	// randomBits is the width of the random field in the standard layout.
+	randomBits = 60
+)
+
+// Layout selects which fields of the ID body a Generator fills from its
+// entropy source. Every layout produces IDs in the same format, decoded by
+// the same Parse; only the source of the random and shard bits differs.
+type Layout uint8
+
+const (
+	// LayoutStandard fills the 60-bit random field from the entropy source
+	// and takes the shard from options. It is the default.
+	LayoutStandard Layout = iota
+	// LayoutCompact fills only the low 32 bits of the random field, leaving
+	// the high 28 bits zero, and reads 4 bytes of entropy per ID instead of
+	// 8. It suits slow hardware entropy sources, at the cost of a much
+	// higher collision probability within a millisecond.
+	LayoutCompact
+	// LayoutExtended also fills the 16-bit shard field from the entropy
+	// source, for 76 random bits per ID. Shard options and the stripe ids of
+	// a StripedGenerator are ignored, WithRegion is rejected, and sequence
+	// state is kept per prefix and tenant.
+	LayoutExtended
+)
+
+// entropyBytes returns how many bytes of entropy l consumes per ID.
+func (l Layout) entropyBytes() int {
+	switch l {
+	case LayoutCompact:
+		return 4
+	case LayoutExtended:
+		return 10
+	default:
+		return 8
+	}
+}
+
+// split decodes the l.entropyBytes() bytes of entropy at the start of b into
+// the random field and the shard, which is only replaced under
+// LayoutExtended.
+func (l Layout) split(b []byte, shard uint16) (uint64, uint16) {
+	switch l {
+	case LayoutCompact:
+		return uint64(binary.BigEndian.Uint32(b)), shard
+	case LayoutExtended:
+		return binary.BigEndian.Uint64(b) & (1<<randomBits - 1), binary.BigEndian.Uint16(b[8:])
+	default:
+		return binary.BigEndian.Uint64(b) & (1<<randomBits - 1), shard
+	}
+}
+
+// EntropyBits reports how many independent random bits each ID minted by g
+// carries: 60 for LayoutStandard, 32 for LayoutCompact, and 76 for
+// LayoutExtended. The sequence, tenant, and, outside LayoutExtended, shard
+// fields are not random and do not count. Clock, sequence start, and entropy
+// fallback settings do not change the result.
+func (g *Generator) EntropyBits() int {
+	switch g.layout {
+	case LayoutCompact:
+		return 32
+	case LayoutExtended:
+		return randomBits + 16
+	default:
+		return randomBits
+	}
+}
+
+// CollisionProbability estimates the chance that at least two of the IDs
+// generated at idsPerMs IDs per millisecond for durationMs milliseconds
+// collide, when each ID carries randomBits independent random bits.
+//
+// It uses the birthday-bound approximation 1 - exp(-k(k-1)/2N) per
+// millisecond, where k is idsPerMs and N = 2^(randomBits+12) is the key space
+// formed by the random field together with the 12-bit sequence, and combines
+// the durationMs independent milliseconds. IDs with different timestamps,
+// prefixes, tenants, or shards never collide, so the estimate applies to a
+// single (prefix, tenant, shard) stream. It returns 0 when fewer than two IDs
+// share a millisecond or durationMs is not positive.
+func CollisionProbability(idsPerMs, durationMs int64, randomBits int) float64 {
+	if idsPerMs < 2 || durationMs <= 0 {
+		return 0
+	}
+	k := float64(idsPerMs)
+	space := math.Ldexp(1, randomBits+seqBits)
+	perMs := k * (k - 1) / (2 * space)
+	return -math.Expm1(-perMs * float64(durationMs))
+}
@@ -0,0 +1,123 @@
+package orderlyid
+
+import (
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestCollisionProbabilityBirthdayBound(t *testing.T) {
+	tests := []struct {
+		name       string
+		idsPerMs   int64
+		durationMs int64
+		randomBits int
+		want       float64
+	}{
+		// k = sqrt(N) gives 1 - e^(-1/2).
+		{name: "sqrt of key space", idsPerMs: 1 << 16, durationMs: 1, randomBits: 20, want: 1 - math.Exp(-0.5*(1-1.0/(1<<16)))},
+		// k(k-1)/2N = ln 2 within one millisecond is the classic 50% point.
+		{name: "half", idsPerMs: 77164, durationMs: 1, randomBits: 20, want: 0.5},
+		{name: "single id", idsPerMs: 1, durationMs: 1000, randomBits: 60, want: 0},
+		{name: "no duration", idsPerMs: 1000, durationMs: 0, randomBits: 60, want: 0},
+		// Standard layout, one million IDs per ms for a day: under 1%.
+		{name: "standard layout", idsPerMs: 1_000_000, durationMs: 86_400_000, randomBits: 60, want: 0.009106},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CollisionProbability(tt.idsPerMs, tt.durationMs, tt.randomBits)
+			if math.Abs(got-tt.want) > 1e-5 {
+				t.Fatalf("CollisionProbability = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollisionProbabilityScalesWithDuration(t *testing.T) {
+	one := CollisionProbability(1000, 1, 40)
+	many := CollisionProbability(1000, 1000, 40)
+	if want := -math.Expm1(1000 * math.Log1p(-one)); math.Abs(many-want) > 1e-12 {
+		t.Fatalf("1000ms = %g, want %g", many, want)
+	}
+	if fewer := CollisionProbability(1000, 1000, 44); fewer >= many {
+		t.Fatalf("more random bits did not lower the estimate: %g >= %g", fewer, many)
+	}
+}
+
+func TestEntropyBitsStandardLayout(t *testing.T) {
+	gens := map[string]*Generator{
+		"default":        NewGenerator(),
+		"sequence start": NewGenerator(WithSequenceStart(100)),
+		"fallback":       NewGenerator(WithEntropyFallback(repeatReader{1})),
+		"standard":       NewGenerator(WithLayout(LayoutStandard)),
+	}
+	for name, g := range gens {
+		if got := g.EntropyBits(); got != 60 {
+			t.Fatalf("%s: EntropyBits = %d, want 60", name, got)
+		}
+	}
+}
+
+func TestEntropyBitsLayouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		layout    Layout
+		want      int
+		random    uint64
+		shard     uint16
+		readBytes int
+	}{
+		{name: "standard", layout: LayoutStandard, want: 60, random: 1<<60 - 1, shard: 7, readBytes: 8},
+		{name: "compact", layout: LayoutCompact, want: 32, random: 1<<32 - 1, shard: 7, readBytes: 4},
+		{name: "extended", layout: LayoutExtended, want: 76, random: 1<<60 - 1, shard: 0xFFFF, readBytes: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &countingReader{r: repeatReader{0xFF}}
+			g := NewGenerator(WithLayout(tt.layout), WithEntropy(r))
+			if got := g.EntropyBits(); got != tt.want {
+				t.Fatalf("EntropyBits = %d, want %d", got, tt.want)
+			}
+
+			// The reported bits are the ones New actually fills.
+			ids := []string{g.New("order", WithShard(7))}
+			ids = append(ids, g.NewBatch("order", 2, WithShard(7))...)
+			ids = append(ids, g.New("order", WithShard(7), WithMonotonicDisabled()))
+			for _, id := range ids {
+				p, err := Parse(id)
+				if err != nil {
+					t.Fatalf("parse %s: %v", id, err)
+				}
+				if p.Random != tt.random || p.Shard != tt.shard {
+					t.Fatalf("%s: random = %#x, shard = %#x, want %#x, %#x", id, p.Random, p.Shard, tt.random, tt.shard)
+				}
+			}
+			if want := tt.readBytes * len(ids); r.n != want {
+				t.Fatalf("read %d entropy bytes, want %d", r.n, want)
+			}
+		})
+	}
+}
+
+func TestLayoutExtendedRejectsRegion(t *testing.T) {
+	g := NewGenerator(WithLayout(LayoutExtended))
+	if _, err := g.NewE("order", WithRegion(3)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("NewE error = %v, want ErrInvalidOption", err)
+	}
+	if _, err := g.newBatch("order", 2, []Option{WithRegion(3)}); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("newBatch error = %v, want ErrInvalidOption", err)
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,128 @@
+package orderlyid
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// FindAll returns the OrderlyIDs with the given prefix that appear in text, in
+// the order they occur.
+//
+// Candidates must be delimited by characters outside [A-Za-z0-9_], so an ID
+// glued to surrounding letters or digits is not matched. When a candidate is
+// followed by "-" and four, six, or eight Base32 characters, the suffix is
+// treated as a checksum and verified; IDs with a mismatching checksum are
+// dropped.
+func FindAll(prefix, text string) []string {
+	var out []string
+	for _, m := range findCandidates(text) {
+		if m.prefix == prefix {
+			out = append(out, m.id)
+		}
+	}
+	return out
+}
+
+// FindAllAny is like FindAll but matches IDs with any valid prefix.
+func FindAllAny(text string) []string {
+	var out []string
+	for _, m := range findCandidates(text) {
+		out = append(out, m.id)
+	}
+	return out
+}
+
+// ParseURL parses the last path segment of rawurl as an OrderlyID.
+//
+// rawurl may be an absolute URL or a bare path such as "/orders/order_...".
+// Trailing slashes, query strings, and fragments are ignored. ParseURL returns
+// an error wrapping ErrInvalidFormat if rawurl is not a valid URL or has no
+// path segment, and otherwise any error returned by Parse.
+func ParseURL(rawurl string) (*Parsed, error) {
+	u, err := url.Parse(strings.TrimSpace(rawurl))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	p := strings.TrimRight(u.Path, "/")
+	if p == "" {
+		return nil, fmt.Errorf("%w: missing path segment", ErrInvalidFormat)
+	}
+	return Parse(path.Base(p))
+}
+
+type candidate struct {
+	prefix string
+	id     string
+}
+
+func findCandidates(text string) []candidate {
+	var out []candidate
+	for off := 0; off < len(text); {
+		j := strings.IndexByte(text[off:], '_')
+		if j < 0 {
+			break
+		}
+		sep := off + j
+		start, end, ok := matchAt(text, sep)
+		if !ok {
+			off = sep + 1
+			continue
+		}
+		out = append(out, candidate{prefix: text[start:sep], id: text[start:end]})
+		off = end
+	}
+	return out
+}
+
+// matchAt attempts to match an ID whose prefix separator is at text[sep] and
+// returns its bounds.
+func matchAt(text string, sep int) (start, end int, ok bool) {
+	start = sep
+	for start > 0 && isPrefixChar(text[start-1]) {
+		start--
+	}
+	if start > 0 && isWordChar(text[start-1]) {
+		return 0, 0, false
+	}
+	if !prefixRe.MatchString(text[start:sep]) {
+		return 0, 0, false
+	}
+	end = sep + 1 + 32
+	if end > len(text) || !validSymbols(text[sep+1:end]) {
+		return 0, 0, false
+	}
+	if end < len(text) && isWordChar(text[end]) {
+		return 0, 0, false
+	}
+	// Optional checksum: "-" followed by exactly four, six, or eight symbols.
+	for n := 4; n <= maxChecksumLen; n += 2 {
+		if cs := end + 1 + n; cs <= len(text) && text[end] == '-' &&
+			(cs == len(text) || !isWordChar(text[cs])) && validSymbols(text[end+1:cs]) {
+			end = cs
+			break
+		}
+	}
+	if _, err := Parse(text[start:end]); err != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+func validSymbols(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !alphaValidMask[s[i]] {
+			return false
+		}
+	}
+	return true
+}
+
+func isPrefixChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
@@ -0,0 +1,85 @@
+package orderlyid
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFindAll(t *testing.T) {
+	order := New("order")
+	orderCS := New("order", WithChecksum(true))
+	user := New("user", WithChecksum(true))
+	badCS := orderCS[:len(orderCS)-4] + flipLast(orderCS[len(orderCS)-4:])
+
+	line := strings.Join([]string{
+		"ts=2025-01-01T00:00:00Z level=info msg=\"created " + order + "\"",
+		"ref=(" + orderCS + ")",
+		"owner=" + user + ",",
+		"tampered=" + badCS,
+		"glued=X" + New("order"),
+		"long=" + New("order") + "z",
+		"short=order_00jc1gmm",
+		"upper=ORDER_00JC1GMM00000000000028T5CY4TQKFF",
+	}, " ")
+
+	if got, want := FindAll("order", line), []string{order, orderCS}; !slices.Equal(got, want) {
+		t.Fatalf("FindAll mismatch:\n got: %v\nwant: %v", got, want)
+	}
+	if got, want := FindAllAny(line), []string{order, orderCS, user}; !slices.Equal(got, want) {
+		t.Fatalf("FindAllAny mismatch:\n got: %v\nwant: %v", got, want)
+	}
+	if got := FindAll("invoice", line); got != nil {
+		t.Fatalf("expected no matches, got %v", got)
+	}
+}
+
+func TestFindAllTreatsNonChecksumSuffixAsBoundary(t *testing.T) {
+	id := New("order")
+	if got := FindAll("order", id+"-retry"); !slices.Equal(got, []string{id}) {
+		t.Fatalf("expected %s, got %v", id, got)
+	}
+}
+
+func flipLast(s string) string {
+	last := s[len(s)-1]
+	repl := byte('0')
+	if last == '0' {
+		repl = '1'
+	}
+	return s[:len(s)-1] + string(repl)
+}
+
+func TestParseURL(t *testing.T) {
+	id := New("order", WithTenant(7))
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "path", url: "/orders/" + id},
+		{name: "trailing slash", url: "/orders/" + id + "/"},
+		{name: "query", url: "/orders/" + id + "?expand=items"},
+		{name: "absolute with fragment", url: "https://api.example.com/v1/orders/" + id + "//?x=1#top"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseURL(tt.url)
+			if err != nil {
+				t.Fatalf("ParseURL(%q): %v", tt.url, err)
+			}
+			if p.Prefix != "order" || p.Tenant != 7 {
+				t.Fatalf("unexpected parse result: %+v", p)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "/", "/orders/", "https://example.com", "%zz"} {
+		if _, err := ParseURL(bad); !errors.Is(err, ErrInvalidFormat) {
+			t.Fatalf("ParseURL(%q): expected ErrInvalidFormat, got %v", bad, err)
+		}
+	}
+	if _, err := ParseURL("/orders/order_123"); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package orderlyid
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/quick"
+)
+
+func FuzzParse(f *testing.F) {
+	if b, err := os.ReadFile(filepath.Join("spec", "test-vectors.json")); err == nil {
+		var v specVectors
+		if json.Unmarshal(b, &v) == nil {
+			for _, vec := range v.Vectors {
+				f.Add(vec.ID)
+			}
+		}
+	}
+	f.Add(New("order", WithChecksum(true)))
+	f.Add("abc-1234")
+	f.Add("order_!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!-abcd")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		p, err := Parse(s)
+		if err != nil {
+			return
+		}
+		canon, err := p.String()
+		if err != nil {
+			t.Fatalf("String after successful Parse(%q): %v", s, err)
+		}
+		again, err := Parse(canon)
+		if err != nil {
+			t.Fatalf("re-parse canonical %q from %q: %v", canon, s, err)
+		}
+		if *again != *p {
+			t.Fatalf("canonical form %q decodes differently:\n got: %+v\nwant: %+v", canon, again, p)
+		}
+		if again.MustString() != canon {
+			t.Fatalf("canonical form %q is not stable", canon)
+		}
+	})
+}
+
+// TestPropertyRoundTrip checks that NewFromParts followed by Parse recovers
+// every field for randomly generated components.
+func TestPropertyRoundTrip(t *testing.T) {
+	prefixes := []string{"ab", "order", "user", "z9y8x7w6v5u4t3s2r1q0p9o8n7m6l5k"}
+	prop := func(ms uint64, flags uint8, tenant, seq, shard uint16, random uint64, pi uint8, checksum bool) bool {
+		c := Components{
+			Prefix:   prefixes[int(pi)%len(prefixes)],
+			TimeMs:   epoch2020 + int64(ms&(1<<48-1)),
+			Flags:    flags &^ FlagVersionMask,
+			Tenant:   tenant,
+			Seq:      seq & 0x0FFF,
+			Shard:    shard,
+			Random60: random & (1<<60 - 1),
+		}
+		id, err := NewFromParts(c, checksum)
+		if err != nil {
+			t.Errorf("NewFromParts(%#v): %v", c, err)
+			return false
+		}
+		p, err := Parse(id)
+		if err != nil {
+			t.Errorf("Parse(%s) from %#v: %v", id, c, err)
+			return false
+		}
+		got := Components{
+			Prefix:   p.Prefix,
+			TimeMs:   p.TimeMs,
+			Flags:    p.Flags,
+			Tenant:   p.Tenant,
+			Seq:      p.Seq,
+			Shard:    p.Shard,
+			Random60: p.Random,
+		}
+		if got != c || p.HasChecksum != checksum {
+			t.Errorf("round trip mismatch for %s\n got: %#v\nwant: %#v", id, got, c)
+			return false
+		}
+		return true
+	}
+	cfg := &quick.Config{MaxCount: 5000, Rand: rand.New(rand.NewSource(20200101))}
+	if err := quick.Check(prop, cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPropertyShardBoundaries walks single-bit shard and seq values, which
+// straddle byte boundaries in the packed layout.
+func TestPropertyShardBoundaries(t *testing.T) {
+	for bit := 0; bit < 16; bit++ {
+		for _, seq := range []uint16{0, 0x0FFF} {
+			c := Components{Prefix: "order", TimeMs: epoch2020, Seq: seq, Shard: 1 << bit, Random60: 1<<60 - 1}
+			id, err := NewFromParts(c, false)
+			if err != nil {
+				t.Fatalf("NewFromParts: %v", err)
+			}
+			p, err := Parse(id)
+			if err != nil {
+				t.Fatalf("Parse(%s): %v", id, err)
+			}
+			if p.Shard != c.Shard || p.Seq != c.Seq || p.Random != c.Random60 {
+				t.Fatalf("shard bit %d seq %#x: got shard=%#x seq=%#x random=%#x", bit, seq, p.Shard, p.Seq, p.Random)
+			}
+		}
+	}
+}
@@ -0,0 +1,808 @@
+package orderlyid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"iter"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time to a Generator.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Generator mints OrderlyIDs. It owns the per-millisecond sequence state, the
+// time source, and the entropy source, so independent Generators never share
+// sequence numbers. Sequence state is kept separately for each combination of
+// prefix, tenant, and shard, so each logical stream of IDs has its own 4096
+// values per millisecond. A Generator is safe for concurrent use.
+//
+// The package-level New uses a default Generator.
+//
+// The 12-bit sequence never repeats within a millisecond: once all 4096
+// values are used, the Generator waits up to a millisecond for the clock to
+// advance and otherwise borrows the next millisecond, so bursts above 4096
+// IDs per millisecond stay unique and increasing at the cost of running
+// slightly ahead of the clock.
+//
+// For reproducible IDs in tests, combine WithClock with a seeded reader:
+//
+//	g := orderlyid.NewGenerator(
+//		orderlyid.WithClock(orderlyid.ClockFunc(func() time.Time { return fixed })),
+//		orderlyid.WithEntropy(rand.NewChaCha8(seed)), // math/rand/v2
+//	)
+//
+// The system clock is read through time.Now, so Generators also follow the
+// fake clock inside a testing/synctest bubble.
+type Generator struct {
+	mu      sync.Mutex
+	clock   Clock
+	entropy io.Reader
+	streams map[streamKey]*seqState
+	pruneAt int    // prune stale streams once len(streams) reaches this
+	seqBase uint16 // 12-bit value a stream's sequence resets to each millisecond
+
+	fallback io.Reader // read when entropy fails; nil means fail
+
+	skewMs      int64  // backward clock steps up to this many ms are absorbed
+	regressions uint64 // backward steps beyond skewMs
+
+	strict    bool // never reuse a (timestamp, sequence) pair
+	monotonic bool // hold the timestamp instead of following the clock back
+
+	jumpGuard bool  // refuse forward clock jumps; see WithAllowFuture
+	err       error // from an invalid GeneratorOption, returned by NewE
+
+	metrics  Metrics
+	alphabet *Alphabet // nil means Crockford
+	epoch    int64     // Unix ms of timestamp zero; epoch2020 by default
+	layout   Layout
+
+	rnd [10]byte // entropy buffer, guarded by mu
+}
+
+// Metrics receives generation events from a Generator, so they can be fed
+// into a metrics system without this package depending on one. Methods are
+// called on the generation hot path, after the Generator's lock is released,
+// and must be safe for concurrent use and cheap.
+type Metrics interface {
+	// IncIssued is called for every ID generated.
+	IncIssued()
+	// IncSeqWrap is called when the 4096 sequence values of a millisecond
+	// are exhausted and the Generator moves to the next millisecond.
+	IncSeqWrap()
+	// IncClockRegression is called for each backward clock step counted by
+	// ClockRegressions.
+	IncClockRegression()
+	// ObserveBlock is called with the time spent waiting for the clock to
+	// advance after the sequence values of a millisecond are exhausted.
+	ObserveBlock(d time.Duration)
+}
+
+// streamKey identifies a logical stream of IDs sharing sequence state.
+type streamKey struct {
+	prefix        string
+	tenant, shard uint16
+}
+
+// seqState is the sequence state of one stream.
+type seqState struct {
+	lastMs  int64
+	clockMs int64  // last clock reading, before any hold or borrowed ms
+	seq12   uint16 // 12-bit
+	used    uint16 // sequence values handed out for lastMs
+	// last unbucketed clock reading (Unix ms) and when it was taken, for
+	// the forward-jump guard; a zero readAt means none
+	readMs int64
+	readAt time.Time
+}
+
+// minPruneAt is the number of streams a Generator tracks before it starts
+// dropping stale ones.
+const minPruneAt = 1024
+
+type nopMetrics struct{}
+
+func (nopMetrics) IncIssued()                 {}
+func (nopMetrics) IncSeqWrap()                {}
+func (nopMetrics) IncClockRegression()        {}
+func (nopMetrics) ObserveBlock(time.Duration) {}
+
+// WithMetrics reports generation events to m. A nil m disables reporting,
+// which is the default.
+func WithMetrics(m Metrics) GeneratorOption {
+	return func(g *Generator) {
+		if m == nil {
+			m = nopMetrics{}
+		}
+		g.metrics = m
+	}
+}
+
+// GeneratorOption configures a Generator in NewGenerator.
+type GeneratorOption func(*Generator)
+
+// WithClock sets the time source. A nil Clock selects the system clock.
+func WithClock(c Clock) GeneratorOption {
+	return func(g *Generator) {
+		g.setClock(c)
+	}
+}
+
+// WithEntropy sets the source of the 60-bit random field. A nil reader selects
+// crypto/rand. Non-cryptographic readers weaken uniqueness guarantees and
+// should only be used in tests.
+func WithEntropy(r io.Reader) GeneratorOption {
+	return func(g *Generator) {
+		g.setEntropy(r)
+	}
+}
+
+// WithEntropyFallback sets a secondary entropy source that is read when the
+// primary source fails. Without a fallback, New panics and NewE returns an
+// error wrapping ErrEntropy on entropy failure. The fallback should be a
+// cryptographically secure reader independent of the primary one; like the
+// primary source, it is read without the lock under WithMonotonicDisabled.
+func WithEntropyFallback(r io.Reader) GeneratorOption {
+	return func(g *Generator) {
+		g.fallback = r
+	}
+}
+
+// WithSequenceStart makes the per-millisecond sequence start at s instead of
+// 0. Only the low 12 bits of s are used. The sequence never wraps: once it
+// reaches 4095 the Generator moves on to the next millisecond, so a start of
+// s leaves 4096-s values per millisecond. Giving each instance a different
+// start makes IDs minted by separate processes in the same millisecond tend
+// to occupy different sequence ranges.
+func WithSequenceStart(s uint16) GeneratorOption {
+	return func(g *Generator) {
+		g.seqBase = s & 0x0FFF
+	}
+}
+
+// WithClockSkewTolerance makes the Generator absorb backward clock steps of up
+// to d, such as small NTP adjustments: while the clock is behind the last
+// timestamp used by at most d, IDs keep that timestamp and advance the
+// sequence, so they stay in increasing order. Larger backward steps are
+// honored and counted; see ClockRegressions.
+func WithClockSkewTolerance(d time.Duration) GeneratorOption {
+	return func(g *Generator) {
+		g.skewMs = d.Milliseconds()
+	}
+}
+
+// WithStrictIntraMsUniqueness guarantees that no two IDs from the Generator
+// share a timestamp and sequence number, so they are unique and strictly
+// increasing even with a constant entropy source. Every Generator already
+// moves to the next millisecond when the sequence runs out or, with
+// WithSequenceStart, reaches 4095; in addition, a clock that steps backwards
+// is held at the last timestamp used instead of regressing.
+//
+// The guarantee covers IDs from one Generator minted with the shared
+// sequence; WithMonotonicDisabled bypasses it.
+func WithStrictIntraMsUniqueness() GeneratorOption {
+	return func(g *Generator) {
+		g.strict = true
+	}
+}
+
+// WithMonotonic makes the Generator hold the last timestamp it used whenever
+// the clock steps backwards, by any amount, such as after an NTP correction or
+// VM migration. IDs minted while the clock is behind keep that timestamp and
+// advance the sequence, so they never sort before IDs issued earlier. Held
+// steps are not counted by ClockRegressions.
+//
+// If the 4096 sequence values of the held millisecond run out, for example
+// under a sustained backward clock, the Generator moves on to the next
+// millisecond instead of wrapping the sequence. Under such load IDs can run
+// ahead of the wall clock until it catches up.
+func WithMonotonic(on bool) GeneratorOption {
+	return func(g *Generator) {
+		g.monotonic = on
+	}
+}
+
+// WithAlphabet makes the Generator encode payloads and checksums with a
+// instead of the Crockford alphabet. IDs it mints must be decoded with the
+// Generator's Parse method; the package-level Parse only accepts Crockford.
+// A nil a selects Crockford.
+func WithAlphabet(a *Alphabet) GeneratorOption {
+	return func(g *Generator) {
+		g.alphabet = a
+	}
+}
+
+// WithEpoch makes the Generator count embedded timestamps from t, truncated
+// to the millisecond, instead of from 2020-01-01 UTC. Times before t are
+// clamped to it, and the 48-bit field then covers about 8900 years from t.
+// If t is the zero time, lies after the Generator's clock reading when it is
+// created, or lies so far before it that the time since t does not fit in
+// the 48-bit field, every NewE and NewBatch call fails with an error wrapping
+// ErrInvalidOption, and New panics.
+//
+// The epoch is part of the ID format, but it is not stored in the ID: an ID
+// minted with a custom epoch parses without error everywhere, and anything
+// except the Generator's own Parse method reads its time wrongly, shifted by
+// the difference between the epochs. This includes the package-level Parse
+// and TimeOf, ParseBinary, ParseSortKey, GobDecode, and implementations in
+// other languages. A Parsed returned by the Generator's Parse remembers the
+// epoch, so its String, Bytes, SortKey, OrderKey, and binary and gob
+// encodings reproduce the ID; its Components do not, since NewFromParts
+// counts from 2020-01-01. Use a custom epoch only for IDs that are decoded
+// solely through Generators configured with the same epoch, and never change
+// the epoch of an existing ID space.
+func WithEpoch(t time.Time) GeneratorOption {
+	return func(g *Generator) {
+		if t.IsZero() {
+			g.err = fmt.Errorf("%w: zero epoch", ErrInvalidOption)
+			return
+		}
+		g.epoch = t.UnixMilli()
+	}
+}
+
+// WithLayout selects which body fields the Generator fills from its entropy
+// source; see Layout. The default is LayoutStandard.
+func WithLayout(l Layout) GeneratorOption {
+	return func(g *Generator) {
+		g.layout = l
+	}
+}
+
+// NewGenerator returns a Generator using the system clock and crypto/rand
+// unless overridden by opts.
+func NewGenerator(opts ...GeneratorOption) *Generator {
+	g := &Generator{
+		clock:   systemClock{},
+		entropy: rand.Reader,
+		streams: make(map[streamKey]*seqState),
+		pruneAt: minPruneAt,
+		metrics: nopMetrics{},
+		epoch:   epoch2020,
+
+		jumpGuard: true,
+	}
+	for _, fn := range opts {
+		fn(g)
+	}
+	if g.err == nil && g.epoch != epoch2020 {
+		g.err = checkEpoch(g.epoch, g.clock.Now().UnixMilli())
+	}
+	return g
+}
+
+// checkEpoch returns an error wrapping ErrInvalidOption unless the time from
+// the epoch to now, both Unix ms, fits in the 48-bit time field.
+func checkEpoch(epoch, now int64) error {
+	if epoch > now {
+		return fmt.Errorf("%w: epoch %v is in the future", ErrInvalidOption, time.UnixMilli(epoch).UTC())
+	}
+	if uint64(now-epoch) >= 1<<48 {
+		return fmt.Errorf("%w: epoch %v is too far in the past for the 48-bit time field", ErrInvalidOption, time.UnixMilli(epoch).UTC())
+	}
+	return nil
+}
+
+// defaultGenerator backs the package-level functions. It does not refuse
+// forward clock jumps, since New would panic on them.
+var defaultGenerator = func() *Generator {
+	g := NewGenerator()
+	g.jumpGuard = false
+	return g
+}()
+
+// SetDefaultClock replaces the time source of the default Generator used by
+// New. A nil Clock restores the system clock. It is safe to call concurrently
+// with New.
+//
+// SetDefaultClock is intended for tests; production code should leave the
+// system clock in place or use a dedicated Generator.
+func SetDefaultClock(c Clock) {
+	defaultGenerator.mu.Lock()
+	defaultGenerator.setClock(c)
+	defaultGenerator.mu.Unlock()
+}
+
+// SetTimeFunc overrides the time source of the default Generator used by New
+// with f. A nil f restores the system clock. It is shorthand for
+// SetDefaultClock(ClockFunc(f)) and is safe to call concurrently with New; f
+// itself is called with the generator lock held and must not call New.
+//
+// SetTimeFunc is intended for tests, for example to freeze time:
+//
+//	orderlyid.SetTimeFunc(func() time.Time { return fixed })
+//	defer orderlyid.SetTimeFunc(nil)
+func SetTimeFunc(f func() time.Time) {
+	if f == nil {
+		SetDefaultClock(nil)
+		return
+	}
+	SetDefaultClock(ClockFunc(f))
+}
+
+// SetDefaultEntropy replaces the entropy source of the default Generator used
+// by New. A nil reader restores crypto/rand. It is safe to call concurrently
+// with New.
+//
+// SetDefaultEntropy is intended for tests; a predictable reader makes IDs
+// guessable and undermines uniqueness.
+func SetDefaultEntropy(r io.Reader) {
+	defaultGenerator.mu.Lock()
+	defaultGenerator.setEntropy(r)
+	defaultGenerator.mu.Unlock()
+}
+
+func (g *Generator) setClock(c Clock) {
+	if c == nil {
+		c = systemClock{}
+	}
+	g.clock = c
+	// A new clock is not a jump of the old one.
+	for _, st := range g.streams {
+		st.readAt = time.Time{}
+	}
+}
+
+func (g *Generator) setEntropy(r io.Reader) {
+	if r == nil {
+		r = rand.Reader
+	}
+	g.entropy = r
+}
+
+// New generates a new OrderlyID. It behaves like the package-level New but
+// uses g's clock, entropy, and sequence state.
+func (g *Generator) New(prefix string, opts ...Option) string {
+	id, err := g.NewE(prefix, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NewE is like New but returns an error instead of panicking. The error wraps
+// ErrInvalidOption for conflicting options or an invalid epoch (see
+// WithEpoch), ErrInvalidPrefix or
+// ErrUnregisteredPrefix for rejected prefixes, ErrFutureTimestamp, once per
+// jump, if g's clock jumps more than a minute ahead of its last reading for
+// the stream (see WithAllowFuture), and ErrEntropy if neither the entropy
+// source nor the fallback could be read.
+func (g *Generator) NewE(prefix string, opts ...Option) (string, error) {
+	o := buildOptions(opts)
+	body, err := g.next(prefix, &o)
+	if err != nil {
+		return "", err
+	}
+	return o.format(prefix, body), nil
+}
+
+// AppendID generates a new ID as by g.New and appends it to dst, returning
+// the extended buffer. It panics under the same conditions as New.
+func (g *Generator) AppendID(dst []byte, prefix string, opts ...Option) []byte {
+	o := buildOptions(opts)
+	body, err := g.next(prefix, &o)
+	if err != nil {
+		panic(err)
+	}
+	return o.appendFormat(dst, prefix, &body)
+}
+
+// next validates o and prefix and generates the body of the next ID. In the
+// default monotonic mode it does not allocate.
+func (g *Generator) next(prefix string, o *options) ([20]byte, error) {
+	if g.err != nil {
+		return [20]byte{}, g.err
+	}
+	o.alphabet = g.alphabet
+	if err := o.validate(); err != nil {
+		return [20]byte{}, err
+	}
+	if err := o.checkPrefix(prefix); err != nil {
+		return [20]byte{}, err
+	}
+
+	flags, shard, err := g.flagsAndShard(o)
+	if err != nil {
+		return [20]byte{}, err
+	}
+
+	var (
+		ms       int64
+		localSeq uint16
+		rnd      [10]byte
+		n        = g.layout.entropyBytes()
+
+		regressed, wrapped bool
+		blocked            time.Duration
+	)
+	if o.entropy != nil {
+		// Reading into rnd directly would move it to the heap on every call.
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(o.entropy, buf); err != nil {
+			return [20]byte{}, fmt.Errorf("%w: %v", ErrEntropy, err)
+		}
+		copy(rnd[:], buf)
+	}
+	if o.noMonotonic || o.hasTime {
+		g.mu.Lock()
+		clock, entropy, fallback := g.clock, g.entropy, g.fallback
+		g.mu.Unlock()
+		if ms, err = o.timestamp(clock, g.epoch); err != nil {
+			return [20]byte{}, err
+		}
+		if o.entropy == nil {
+			buf := make([]byte, n)
+			err = readEntropy(entropy, fallback, buf)
+			copy(rnd[:], buf)
+		}
+	} else {
+		g.mu.Lock()
+		raw := g.clock.Now().UnixMilli()
+		ms = o.stamp(raw, g.epoch)
+		st := g.stream(streamKey{prefix, o.tenant, shard}, ms)
+		if err = st.checkJump(o, raw, g.jumpGuard); err != nil {
+			g.mu.Unlock()
+			return [20]byte{}, err
+		}
+		ms, regressed = g.hold(st, ms)
+		// As in NewBatch, spill before a sequence started by
+		// WithSequenceStart wraps to 0 and breaks the ordering.
+		if ms == st.lastMs && (st.used >= 1<<seqBits || st.seq12 == 0x0FFF) {
+			wrapped = true
+			if g.monotonic {
+				ms = st.lastMs + 1
+			} else {
+				start := time.Now()
+				ms, err = g.nextMs(o, st.lastMs)
+				blocked = time.Since(start)
+				if err != nil {
+					g.mu.Unlock()
+					return [20]byte{}, err
+				}
+			}
+		}
+		localSeq = st.take(ms, g.seqBase)
+
+		// random bits; read under the lock so non-concurrent readers are
+		// safe, into g.rnd so that no buffer escapes per call
+		if o.entropy == nil {
+			err = readEntropy(g.entropy, g.fallback, g.rnd[:n])
+			rnd = g.rnd
+		}
+		g.mu.Unlock()
+	}
+	if regressed {
+		g.metrics.IncClockRegression()
+	}
+	if wrapped {
+		g.metrics.IncSeqWrap()
+	}
+	if blocked > 0 {
+		g.metrics.ObserveBlock(blocked)
+	}
+	if err != nil {
+		return [20]byte{}, err
+	}
+
+	random60, shard := g.layout.split(rnd[:], shard)
+	body := pack(uint64(ms), flags, o.tenant, localSeq, shard, random60)
+	g.metrics.IncIssued()
+	return body, nil
+}
+
+// Parse decodes an ID minted by g. It behaves like the package-level Parse but
+// reads the payload and checksum with g's alphabet and the timestamp relative
+// to g's epoch; for a custom Alphabet, case is significant and no aliases are
+// accepted.
+func (g *Generator) Parse(s string) (*Parsed, error) {
+	a := g.alphabet
+	if a == nil {
+		a = crockford
+	}
+	p := new(Parsed)
+	if err := a.parseInto(s, p, false, MaxVersion); err != nil {
+		return nil, err
+	}
+	p.epochShift = g.epoch - epoch2020
+	p.TimeMs += p.epochShift
+	return p, nil
+}
+
+// ClockRegressions reports how many times g has seen its clock step backwards
+// by more than the tolerance set with WithClockSkewTolerance (zero by
+// default). IDs minted after such a step sort before earlier ones. Steps
+// absorbed under WithStrictIntraMsUniqueness are not counted.
+func (g *Generator) ClockRegressions() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.regressions
+}
+
+// flagsAndShard returns the flags byte and shard field selected by o.
+func (o *options) flagsAndShard() (uint8, uint16) {
+	flags := o.flags
+	if o.bucketSeconds > 0 {
+		flags |= FlagPrivacy
+	}
+	shard := o.shard
+	if o.hasRegion {
+		flags |= FlagRegion
+		shard = shard&0x0FFF | uint16(o.region)<<12
+	}
+	// version in bits 7..6 already 0
+	return flags, shard
+}
+
+// flagsAndShard is like o.flagsAndShard but applies g's layout: under
+// LayoutExtended the shard, filled from entropy later, starts at zero, and
+// WithRegion, which needs the shard field, is rejected.
+func (g *Generator) flagsAndShard(o *options) (uint8, uint16, error) {
+	flags, shard := o.flagsAndShard()
+	if g.layout != LayoutExtended {
+		return flags, shard, nil
+	}
+	if o.hasRegion {
+		return 0, 0, fmt.Errorf("%w: WithRegion needs the shard field, which LayoutExtended fills randomly", ErrInvalidOption)
+	}
+	return flags, 0, nil
+}
+
+// hold applies g's policy for backward clock steps to the clock reading ms
+// for st. It returns the timestamp to use and whether the step was counted
+// as a regression. g.mu must be held.
+func (g *Generator) hold(st *seqState, ms int64) (int64, bool) {
+	raw := ms
+	regressed := false
+	if ms < st.lastMs {
+		// A clock that has not moved back past its previous reading only
+		// trails a borrowed millisecond; hold it rather than regress.
+		if ms >= st.clockMs || st.lastMs-ms <= g.skewMs || g.strict || g.monotonic {
+			ms = st.lastMs
+		} else {
+			g.regressions++
+			regressed = true
+		}
+	}
+	st.clockMs = raw
+	return ms, regressed
+}
+
+// take hands out the next sequence value of st for timestamp ms, restarting
+// at base when ms differs from the last timestamp used.
+func (st *seqState) take(ms int64, base uint16) uint16 {
+	if ms == st.lastMs {
+		st.seq12 = (st.seq12 + 1) & 0x0FFF
+		st.used++
+	} else {
+		st.lastMs = ms
+		st.seq12 = base
+		st.used = 1
+	}
+	return st.seq12
+}
+
+// checkJump returns an error wrapping ErrFutureTimestamp if guard is set and
+// the clock reading raw (Unix ms, before bucketing) is more than
+// futureThreshold ahead of the last reading for st, beyond the real time that
+// has passed since, unless o allows future timestamps. Either way it records
+// raw as the new reference, so a step that persists, such as an NTP
+// correction, is refused once and then followed. Fresh streams, and streams
+// last used under a different clock, are not checked.
+func (st *seqState) checkJump(o *options, raw int64, guard bool) error {
+	if raw == st.readMs && !st.readAt.IsZero() {
+		return nil
+	}
+	now := time.Now()
+	var ahead time.Duration
+	if guard && !o.allowFuture && !st.readAt.IsZero() {
+		ahead = time.Duration(raw-st.readMs)*time.Millisecond - now.Sub(st.readAt)
+	}
+	st.readMs, st.readAt = raw, now
+	if ahead > futureThreshold {
+		return fmt.Errorf("%w: clock jumped %v ahead of its last reading", ErrFutureTimestamp, ahead.Round(time.Second))
+	}
+	return nil
+}
+
+// stream returns the sequence state for k, creating it if needed. When the
+// number of streams reaches g.pruneAt, streams idle for more than a second
+// before ms are dropped first; a dropped stream starts afresh if it is used
+// again. g.mu must be held.
+func (g *Generator) stream(k streamKey, ms int64) *seqState {
+	if st, ok := g.streams[k]; ok {
+		return st
+	}
+	if len(g.streams) >= g.pruneAt {
+		for key, st := range g.streams {
+			if st.lastMs < ms-1000 {
+				delete(g.streams, key)
+			}
+		}
+		g.pruneAt = max(minPruneAt, 2*len(g.streams))
+	}
+	st := new(seqState)
+	g.streams[k] = st
+	return st
+}
+
+// nextMs waits briefly for g's clock to pass lastMs and returns the new
+// timestamp, or lastMs+1 if the clock does not advance in time. g.mu must be
+// held.
+func (g *Generator) nextMs(o *options, lastMs int64) (int64, error) {
+	deadline := time.Now().Add(time.Millisecond)
+	for time.Now().Before(deadline) {
+		runtime.Gosched()
+		ms, err := o.timestamp(g.clock, g.epoch)
+		if err != nil {
+			return 0, err
+		}
+		if ms > lastMs {
+			return ms, nil
+		}
+	}
+	return lastMs + 1, nil
+}
+
+// Stream returns an iterator that lazily yields new IDs from g until the
+// consumer stops. Each ID is generated as by g.New(prefix, opts...) when it is
+// requested, so IDs from one Stream are unique and in increasing order as long
+// as the clock does not move backwards:
+//
+//	for id := range g.Stream("order") {
+//		if !emit(id) {
+//			break
+//		}
+//	}
+//
+// Stream panics under the same conditions as New.
+func (g *Generator) Stream(prefix string, opts ...Option) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for {
+			if !yield(g.New(prefix, opts...)) {
+				return
+			}
+		}
+	}
+}
+
+// StreamChan is like Stream but delivers IDs on a channel for consumers such
+// as fan-out workers that each pull the next ID. The channel is unbuffered,
+// so an ID is generated only when the previous one has been received. A
+// goroutine feeds the channel until ctx is done and then closes it. Errors
+// that would make New panic instead end the stream: the channel is closed
+// without further IDs.
+func (g *Generator) StreamChan(ctx context.Context, prefix string, opts ...Option) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for ctx.Err() == nil {
+			id, err := g.NewE(prefix, opts...)
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// readEntropy fills b from r, trying fallback if r fails.
+func readEntropy(r, fallback io.Reader, b []byte) error {
+	_, err := io.ReadFull(r, b)
+	if err == nil {
+		return nil
+	}
+	if fallback != nil {
+		if _, ferr := io.ReadFull(fallback, b); ferr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrEntropy, err)
+}
+
+// format renders body with prefix according to the checksum and display
+// options.
+func (o *options) format(prefix string, body [20]byte) string {
+	return string(o.appendFormat(make([]byte, 0, len(prefix)+1+32+5), prefix, &body))
+}
+
+// appendFormat appends the textual form of body with prefix to dst.
+func (o *options) appendFormat(dst []byte, prefix string, body *[20]byte) []byte {
+	a := o.alphabet
+	if a == nil {
+		a = crockford
+	}
+	var payload [32]byte
+	a.put(&payload, body)
+	var cs []byte
+	if n := o.checksumLenOf(); n > 0 {
+		sum := a.checksumN(prefix, string(payload[:]), n)
+		cs = sum[:n]
+	}
+	dst = append(dst, prefix...)
+	dst = append(dst, '_')
+	for i, c := range payload {
+		if o.groupSize > 0 && i > 0 && i%o.groupSize == 0 {
+			dst = append(dst, '-')
+		}
+		dst = append(dst, o.outputCase(c))
+	}
+	if cs != nil {
+		dst = append(dst, '-')
+		for _, c := range cs {
+			dst = append(dst, o.outputCase(c))
+		}
+	}
+	return dst
+}
+
+func (o *options) outputCase(c byte) byte {
+	if o.uppercase && c >= 'a' && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
+
+// futureThreshold is how far a Generator's clock may jump ahead of its last
+// reading for a stream, and how far a WithTime time may lie in the future,
+// before New refuses it, unless WithAllowFuture is given.
+const futureThreshold = time.Minute
+
+// timestamp returns the time given by WithTime, or else reads c, as
+// milliseconds since epoch (Unix ms) rounded down to the configured bucket.
+// Times before the epoch are clamped to it. It fails as readClock does.
+func (o *options) timestamp(c Clock, epoch int64) (int64, error) {
+	raw, err := o.readClock(c)
+	if err != nil {
+		return 0, err
+	}
+	return o.stamp(raw, epoch), nil
+}
+
+// readClock returns the time given by WithTime, or else reads c, in Unix ms.
+// It fails if a WithTime time is ahead of the system clock by more than
+// futureThreshold and future timestamps are not allowed; clock readings are
+// checked against the stream by checkJump instead.
+func (o *options) readClock(c Clock) (int64, error) {
+	if !o.hasTime {
+		return c.Now().UnixMilli(), nil
+	}
+	if ahead := time.Until(o.at); ahead > futureThreshold && !o.allowFuture {
+		return 0, fmt.Errorf("%w: time is %v ahead of the system clock", ErrFutureTimestamp, ahead.Round(time.Second))
+	}
+	return o.at.UnixMilli(), nil
+}
+
+// stamp rounds the Unix ms time raw down to the configured bucket and
+// returns it in milliseconds since epoch, clamped at 0.
+func (o *options) stamp(raw, epoch int64) int64 {
+	if o.bucketSeconds > 0 {
+		bs := int64(o.bucketSeconds) * 1000
+		raw = (raw / bs) * bs
+	}
+	return max(raw-epoch, 0)
+}
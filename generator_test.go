@@ -0,0 +1,872 @@
+package orderlyid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	mrand "math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// repeatReader yields its bytes over and over.
+type repeatReader []byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r[i%len(r)]
+	}
+	return len(p), nil
+}
+
+func fixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+func TestDefaultGeneratorDeterministic(t *testing.T) {
+	SetDefaultClock(fixedClock(time.UnixMilli(1735689600000)))
+	SetDefaultEntropy(repeatReader{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef})
+	defer SetDefaultClock(nil)
+	defer SetDefaultEntropy(nil)
+
+	// Matches the first spec vector: same time, zero fields, same random.
+	if got, want := New("order"), "order_00jc1gmm00000000000028t5cy4tqkff"; got != want {
+		t.Fatalf("New = %s, want %s", got, want)
+	}
+	p, err := Parse(New("order"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if p.TimeMs != 1735689600000 || p.Seq != 1 || p.Random != 0x0123456789abcdef {
+		t.Fatalf("second ID in the same ms: %+v", p)
+	}
+}
+
+func TestGeneratorsHaveIndependentSequences(t *testing.T) {
+	clock := fixedClock(time.UnixMilli(1735689600000))
+	g1 := NewGenerator(WithClock(clock))
+	g2 := NewGenerator(WithClock(clock))
+	for want := uint16(0); want < 3; want++ {
+		for _, g := range []*Generator{g1, g2} {
+			p, err := Parse(g.New("order"))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if p.Seq != want {
+				t.Fatalf("seq = %d, want %d", p.Seq, want)
+			}
+		}
+	}
+}
+
+func TestSetTimeFuncFreezesTime(t *testing.T) {
+	frozen := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+	SetTimeFunc(func() time.Time { return frozen })
+	defer SetTimeFunc(nil)
+
+	for i := 0; i < 10; i++ {
+		p, err := Parse(New("order"))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if p.TimeMs != frozen.UnixMilli() {
+			t.Fatalf("call %d: time = %d, want %d", i, p.TimeMs, frozen.UnixMilli())
+		}
+		if p.Seq != uint16(i) {
+			t.Fatalf("call %d: seq = %d, want %d", i, p.Seq, i)
+		}
+	}
+
+	SetTimeFunc(nil)
+	p, err := Parse(New("order"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if time.Since(time.UnixMilli(p.TimeMs)) > time.Minute {
+		t.Fatalf("SetTimeFunc(nil) did not restore the system clock: %d", p.TimeMs)
+	}
+}
+
+func TestWithSequenceStartSeparatesRanges(t *testing.T) {
+	clock := fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	a := NewGenerator(WithClock(clock))
+	b := NewGenerator(WithClock(clock), WithSequenceStart(2048))
+
+	const n = 64
+	seen := make(map[uint16]bool, n)
+	for i := 0; i < n; i++ {
+		p, err := Parse(a.New("order"))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		seen[p.Seq] = true
+	}
+	for i := 0; i < n; i++ {
+		p, err := Parse(b.New("order"))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if want := uint16(2048 + i); p.Seq != want {
+			t.Fatalf("call %d: seq = %d, want %d", i, p.Seq, want)
+		}
+		if seen[p.Seq] {
+			t.Fatalf("seq %d used by both generators", p.Seq)
+		}
+	}
+}
+
+func TestWithSequenceStartSpillsInsteadOfWrapping(t *testing.T) {
+	frozen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(WithClock(fixedClock(frozen)), WithSequenceStart(4094))
+	want := []struct {
+		ms  int64
+		seq uint16
+	}{
+		{0, 4094}, {0, 4095}, {1, 4094}, {1, 4095}, {2, 4094},
+	}
+	prev := ""
+	for i, w := range want {
+		id := g.New("order")
+		if id <= prev {
+			t.Fatalf("call %d: %s not above %s", i, id, prev)
+		}
+		prev = id
+		p := mustParse(t, id)
+		if p.TimeMs != frozen.UnixMilli()+w.ms || p.Seq != w.seq {
+			t.Fatalf("call %d: time +%d seq %d, want +%d seq %d", i, p.TimeMs-frozen.UnixMilli(), p.Seq, w.ms, w.seq)
+		}
+	}
+
+	// Only the low 12 bits of the start are used.
+	g = NewGenerator(WithClock(fixedClock(frozen)), WithSequenceStart(0xFFFF))
+	a, b := mustParse(t, g.New("order")), mustParse(t, g.New("order"))
+	if a.Seq != 0x0FFF || b.Seq != 0x0FFF || b.TimeMs != a.TimeMs+1 {
+		t.Fatalf("start 0xFFFF: got %+v then %+v", a, b)
+	}
+}
+
+func TestWithMonotonicDisabledKeepsSeqZero(t *testing.T) {
+	g := NewGenerator(WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))))
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := g.New("order", WithMonotonicDisabled())
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if p.Seq != 0 {
+			t.Fatalf("call %d: seq = %d, want 0", i, p.Seq)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %s", id)
+		}
+		seen[id] = true
+	}
+
+	// The shared counter is untouched, so monotonic calls still start at 0.
+	p, err := Parse(g.New("order"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if p.Seq != 0 {
+		t.Fatalf("monotonic seq = %d, want 0", p.Seq)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+func TestNewEReportsEntropyFailure(t *testing.T) {
+	g := NewGenerator(WithEntropy(failingReader{}))
+	if _, err := g.NewE("order"); !errors.Is(err, ErrEntropy) {
+		t.Fatalf("expected ErrEntropy, got %v", err)
+	}
+	if _, err := g.NewE("order", WithMonotonicDisabled()); !errors.Is(err, ErrEntropy) {
+		t.Fatalf("expected ErrEntropy without monotonic, got %v", err)
+	}
+	if _, err := g.NewE("Bad!"); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+}
+
+func TestEntropyFallback(t *testing.T) {
+	g := NewGenerator(
+		WithClock(fixedClock(time.UnixMilli(1735689600000))),
+		WithEntropy(failingReader{}),
+		WithEntropyFallback(repeatReader{0xAB}),
+	)
+	id, err := g.NewE("order")
+	if err != nil {
+		t.Fatalf("NewE with fallback: %v", err)
+	}
+	p, err := Parse(id)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if want := uint64(0x0BABABABABABABAB); p.Random != want {
+		t.Fatalf("random = %#x, want %#x from fallback", p.Random, want)
+	}
+}
+
+func TestStreamYieldsUniqueOrderedIDs(t *testing.T) {
+	g := NewGenerator()
+	const n = 1000
+	var ids []string
+	for id := range g.Stream("order", WithTenant(2)) {
+		ids = append(ids, id)
+		if len(ids) == n {
+			break
+		}
+	}
+	if len(ids) != n {
+		t.Fatalf("got %d ids, want %d", len(ids), n)
+	}
+	for i, id := range ids {
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse %s: %v", id, err)
+		}
+		if p.Tenant != 2 {
+			t.Fatalf("tenant = %d, want 2", p.Tenant)
+		}
+		if i > 0 && ids[i-1] >= id {
+			t.Fatalf("ids out of order at %d: %s >= %s", i, ids[i-1], id)
+		}
+	}
+}
+
+func mustParse(t *testing.T, id string) *Parsed {
+	t.Helper()
+	p, err := Parse(id)
+	if err != nil {
+		t.Fatalf("parse %s: %v", id, err)
+	}
+	return p
+}
+
+// steppingClock returns the times in ts one per call, repeating the last.
+func steppingClock(ts ...time.Time) Clock {
+	var i int
+	return ClockFunc(func() time.Time {
+		t := ts[min(i, len(ts)-1)]
+		i++
+		return t
+	})
+}
+
+func TestStreamChanClosesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := NewGenerator().StreamChan(ctx, "order", WithTenant(2))
+	prev := ""
+	for i := 0; i < 10; i++ {
+		id, ok := <-ch
+		if !ok {
+			t.Fatalf("channel closed after %d ids", i)
+		}
+		if id <= prev {
+			t.Fatalf("id %d: %s not above %s", i, id, prev)
+		}
+		prev = id
+	}
+	cancel()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("channel not closed after cancel")
+		}
+	}
+}
+
+func TestStreamChanStopsOnError(t *testing.T) {
+	ch := NewGenerator().StreamChan(context.Background(), "Order")
+	select {
+	case id, ok := <-ch:
+		if ok {
+			t.Fatalf("received %s for an invalid prefix", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("channel not closed after error")
+	}
+}
+
+func TestClockSkewTolerance(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(
+		WithClock(steppingClock(base, base.Add(-3*time.Millisecond), base.Add(-time.Second))),
+		WithClockSkewTolerance(5*time.Millisecond),
+	)
+	p1 := mustParse(t, g.New("order"))
+	p2 := mustParse(t, g.New("order"))
+	if p2.TimeMs != p1.TimeMs || p2.Seq != p1.Seq+1 {
+		t.Fatalf("small step not absorbed: %+v then %+v", p1, p2)
+	}
+	if n := g.ClockRegressions(); n != 0 {
+		t.Fatalf("ClockRegressions = %d after small step, want 0", n)
+	}
+
+	p3 := mustParse(t, g.New("order"))
+	if p3.TimeMs != base.Add(-time.Second).UnixMilli() {
+		t.Fatalf("large step absorbed: time = %d", p3.TimeMs)
+	}
+	if n := g.ClockRegressions(); n != 1 {
+		t.Fatalf("ClockRegressions = %d after large step, want 1", n)
+	}
+}
+
+func TestFutureClockGuard(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	jump := base.Add(2 * time.Minute)
+	g := NewGenerator(WithClock(steppingClock(base, jump)))
+	first := mustParse(t, g.New("order"))
+	if _, err := g.NewE("order"); !errors.Is(err, ErrFutureTimestamp) {
+		t.Fatalf("expected ErrFutureTimestamp after a forward jump, got %v", err)
+	}
+	// The jump is refused once; after that the new time is the reference.
+	id, err := g.NewE("order")
+	if err != nil {
+		t.Fatalf("NewE after a refused jump: %v", err)
+	}
+	if p := mustParse(t, id); p.TimeMs != jump.UnixMilli() {
+		t.Fatalf("time = %d, want %d", p.TimeMs, jump.UnixMilli())
+	}
+	if first.TimeMs != base.UnixMilli() {
+		t.Fatalf("first time = %d, want %d", first.TimeMs, base.UnixMilli())
+	}
+
+	batch := NewGenerator(WithClock(steppingClock(base, jump)))
+	batch.New("order")
+	if _, err := batch.newBatch("order", 2, nil); !errors.Is(err, ErrFutureTimestamp) {
+		t.Fatalf("expected ErrFutureTimestamp from NewBatch, got %v", err)
+	}
+	allowed := NewGenerator(WithClock(steppingClock(base, jump)))
+	allowed.New("order")
+	if _, err := allowed.NewE("order", WithAllowFuture()); err != nil {
+		t.Fatalf("NewE with WithAllowFuture: %v", err)
+	}
+
+	// Jumps within the threshold, and jumps matched by real time passing,
+	// are accepted.
+	near := NewGenerator(WithClock(steppingClock(base, base.Add(10*time.Second))))
+	near.New("order")
+	if _, err := near.NewE("order"); err != nil {
+		t.Fatalf("NewE with small jump: %v", err)
+	}
+	idle := NewGenerator(WithClock(steppingClock(base, jump)))
+	idle.New("order")
+	for _, st := range idle.streams {
+		st.readAt = st.readAt.Add(-2 * time.Minute)
+	}
+	if _, err := idle.NewE("order"); err != nil {
+		t.Fatalf("NewE after an idle period: %v", err)
+	}
+	// Bucketing does not move the reference: a bucketed ID followed by an
+	// unbucketed one in the same stream is not a jump.
+	bucketed := NewGenerator(WithClock(fixedClock(base.Add(59 * time.Minute))))
+	bucketed.New("order", WithBucketSeconds(3600))
+	if _, err := bucketed.NewE("order"); err != nil {
+		t.Fatalf("NewE after a bucketed ID: %v", err)
+	}
+
+	// Replacing the clock is not a jump.
+	g.setClock(fixedClock(base.Add(time.Hour)))
+	if _, err := g.NewE("order"); err != nil {
+		t.Fatalf("NewE after replacing the clock: %v", err)
+	}
+}
+
+func TestFutureClockGuardRecovers(t *testing.T) {
+	// The wall clock steps two minutes forward and stays there while real
+	// time keeps advancing.
+	var offset atomic.Int64
+	clock := ClockFunc(func() time.Time {
+		return time.Now().Add(time.Duration(offset.Load()))
+	})
+	g := NewGenerator(WithClock(clock))
+	g.New("order")
+	offset.Store(int64(2 * time.Minute))
+	if _, err := g.NewE("order"); !errors.Is(err, ErrFutureTimestamp) {
+		t.Fatalf("expected ErrFutureTimestamp after a forward jump, got %v", err)
+	}
+	prev := ""
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond)
+		id, err := g.NewE("order")
+		if err != nil {
+			t.Fatalf("NewE %d after the jump: %v", i, err)
+		}
+		if id <= prev {
+			t.Fatalf("IDs out of order: %s <= %s", id, prev)
+		}
+		prev = id
+	}
+
+	// The default Generator never refuses a jump, so New does not panic.
+	SetDefaultClock(clock)
+	defer SetDefaultClock(nil)
+	offset.Store(0)
+	New("order")
+	offset.Store(int64(4 * time.Minute))
+	if _, err := NewE("order"); err != nil {
+		t.Fatalf("package NewE after a forward jump: %v", err)
+	}
+}
+
+func TestStrictIntraMsUniquenessFrozenClock(t *testing.T) {
+	frozen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(
+		WithClock(fixedClock(frozen)),
+		WithEntropy(repeatReader{0}),
+		WithStrictIntraMsUniqueness(),
+	)
+	const n = 3*4096 + 10
+	prev := ""
+	for i := 0; i < n; i++ {
+		id := g.New("order")
+		if id <= prev {
+			t.Fatalf("call %d: %s not above %s", i, id, prev)
+		}
+		prev = id
+	}
+	if p := mustParse(t, prev); p.TimeMs != frozen.UnixMilli()+3 {
+		t.Fatalf("last time = %d, want 3ms borrowed past %d", p.TimeMs, frozen.UnixMilli())
+	}
+}
+
+func TestStrictIntraMsUniquenessWithSequenceStart(t *testing.T) {
+	frozen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(
+		WithClock(fixedClock(frozen)),
+		WithEntropy(repeatReader{0}),
+		WithSequenceStart(4090),
+		WithStrictIntraMsUniqueness(),
+	)
+	prev := ""
+	for i := 0; i < 20; i++ {
+		id := g.New("order")
+		if id <= prev {
+			t.Fatalf("call %d: %s not above %s", i, id, prev)
+		}
+		prev = id
+	}
+	if p := mustParse(t, prev); p.TimeMs != frozen.UnixMilli()+3 || p.Seq != 4091 {
+		t.Fatalf("last = time %d seq %d, want time %d seq 4091", p.TimeMs, p.Seq, frozen.UnixMilli()+3)
+	}
+}
+
+func TestStrictIntraMsUniquenessConcurrent(t *testing.T) {
+	g := NewGenerator(WithEntropy(repeatReader{0}), WithStrictIntraMsUniqueness())
+	const workers, perWorker = 8, 5000
+	results := make([][]string, workers)
+	var wg sync.WaitGroup
+	for w := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, perWorker)
+			for i := range ids {
+				ids[i] = g.New("order")
+			}
+			results[w] = ids
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, workers*perWorker)
+	for _, ids := range results {
+		for i, id := range ids {
+			if seen[id] {
+				t.Fatalf("duplicate id %s", id)
+			}
+			seen[id] = true
+			if i > 0 && ids[i-1] >= id {
+				t.Fatalf("ids out of order within a goroutine: %s >= %s", ids[i-1], id)
+			}
+		}
+	}
+}
+
+func TestStrictIntraMsUniquenessHoldsBackwardClock(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(
+		WithClock(steppingClock(base, base.Add(-time.Second))),
+		WithStrictIntraMsUniqueness(),
+	)
+	a := g.New("order")
+	b := g.New("order")
+	if b <= a {
+		t.Fatalf("backward step regressed: %s then %s", a, b)
+	}
+	if n := g.ClockRegressions(); n != 0 {
+		t.Fatalf("ClockRegressions = %d, want 0", n)
+	}
+}
+
+type fakeMetrics struct {
+	mu                              sync.Mutex
+	issued, wraps, regressions, blk int
+}
+
+func (m *fakeMetrics) IncIssued()          { m.mu.Lock(); m.issued++; m.mu.Unlock() }
+func (m *fakeMetrics) IncSeqWrap()         { m.mu.Lock(); m.wraps++; m.mu.Unlock() }
+func (m *fakeMetrics) IncClockRegression() { m.mu.Lock(); m.regressions++; m.mu.Unlock() }
+func (m *fakeMetrics) ObserveBlock(d time.Duration) {
+	m.mu.Lock()
+	if d > 0 {
+		m.blk++
+	}
+	m.mu.Unlock()
+}
+
+func TestMetricsCallbacks(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := make([]time.Time, 0, 4099)
+	for i := 0; i < 4097; i++ {
+		ts = append(ts, base)
+	}
+	ts = append(ts, base.Add(-time.Second))
+
+	m := &fakeMetrics{}
+	g := NewGenerator(WithClock(steppingClock(ts...)), WithMetrics(m))
+	for i := 0; i < 4098; i++ {
+		g.New("order")
+	}
+	if m.issued != 4098 || m.wraps != 1 || m.regressions != 1 || m.blk != 1 {
+		t.Fatalf("metrics = issued %d, wraps %d, regressions %d, blocks %d; want 4098, 1, 1, 1",
+			m.issued, m.wraps, m.regressions, m.blk)
+	}
+
+	strictM := &fakeMetrics{}
+	strict := NewGenerator(WithClock(fixedClock(base)), WithStrictIntraMsUniqueness(), WithMetrics(strictM))
+	for i := 0; i < 4097; i++ {
+		strict.New("order")
+	}
+	if strictM.issued != 4097 || strictM.wraps != 1 || strictM.blk != 1 {
+		t.Fatalf("strict metrics = issued %d, wraps %d, blocks %d; want 4097, 1, 1",
+			strictM.issued, strictM.wraps, strictM.blk)
+	}
+
+	if _, err := NewGenerator(WithMetrics(nil)).NewE("order"); err != nil {
+		t.Fatalf("NewE with nil metrics: %v", err)
+	}
+}
+
+func TestGeneratorReproducibleWithSeededRand(t *testing.T) {
+	mk := func() *Generator {
+		return NewGenerator(
+			WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))),
+			WithEntropy(mrand.NewChaCha8([32]byte{1, 2, 3})),
+		)
+	}
+	a, b := mk(), mk()
+	for i := 0; i < 10; i++ {
+		if x, y := a.New("order"), b.New("order"); x != y {
+			t.Fatalf("call %d: %s != %s with identical seeds", i, x, y)
+		}
+	}
+	if a.New("order") == NewGenerator().New("order") {
+		t.Fatalf("seeded generator matches the default generator")
+	}
+}
+
+func TestWithTime(t *testing.T) {
+	g := NewGenerator()
+	at := time.Date(2021, 7, 4, 9, 30, 15, 250_000_000, time.UTC)
+
+	p := mustParse(t, g.New("order", WithTime(at)))
+	if p.TimeMs != at.UnixMilli() || p.Seq != 0 {
+		t.Fatalf("time = %d seq = %d, want %d and 0", p.TimeMs, p.Seq, at.UnixMilli())
+	}
+	bucketed := mustParse(t, g.New("order", WithTime(at), WithBucketSeconds(60)))
+	if want := time.Date(2021, 7, 4, 9, 30, 0, 0, time.UTC).UnixMilli(); bucketed.TimeMs != want || !bucketed.IsPrivacyBucketed() {
+		t.Fatalf("bucketed time = %d, want %d", bucketed.TimeMs, want)
+	}
+	if pre := mustParse(t, g.New("order", WithTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)))); pre.TimeMs != epoch2020 {
+		t.Fatalf("pre-epoch time = %d, want clamp to %d", pre.TimeMs, epoch2020)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if _, err := g.NewE("order", WithTime(future)); !errors.Is(err, ErrFutureTimestamp) {
+		t.Fatalf("expected ErrFutureTimestamp, got %v", err)
+	}
+	if _, err := g.NewE("order", WithTime(future), WithAllowFuture()); err != nil {
+		t.Fatalf("NewE with WithAllowFuture: %v", err)
+	}
+
+	// Explicit times leave the live sequence alone.
+	live1 := mustParse(t, g.New("order"))
+	g.New("order", WithTime(at))
+	live2 := mustParse(t, g.New("order"))
+	if live2.TimeMs == live1.TimeMs && live2.Seq != live1.Seq+1 {
+		t.Fatalf("explicit time disturbed the sequence: %d then %d", live1.Seq, live2.Seq)
+	}
+}
+
+func TestWithRandomSource(t *testing.T) {
+	g := NewGenerator(WithEntropy(failingReader{}))
+	id, err := g.NewE("order", WithRandomSource(repeatReader{0xFF}))
+	if err != nil {
+		t.Fatalf("NewE: %v", err)
+	}
+	if p := mustParse(t, id); p.Random != 1<<60-1 {
+		t.Fatalf("random = %#x, want the top 4 bits masked", p.Random)
+	}
+
+	short := strings.NewReader("1234567")
+	if _, err := NewGenerator().NewE("order", WithRandomSource(short)); !errors.Is(err, ErrEntropy) {
+		t.Fatalf("expected ErrEntropy for a short read, got %v", err)
+	}
+	fb := NewGenerator(WithEntropyFallback(repeatReader{1}))
+	if _, err := fb.NewE("order", WithRandomSource(failingReader{})); !errors.Is(err, ErrEntropy) {
+		t.Fatalf("expected ErrEntropy despite the generator fallback, got %v", err)
+	}
+}
+
+func TestAppendIDMatchesNew(t *testing.T) {
+	mk := func() *Generator {
+		return NewGenerator(
+			WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))),
+			WithEntropy(mrand.NewChaCha8([32]byte{4, 5, 6})),
+		)
+	}
+	a, b := mk(), mk()
+	optSets := [][]Option{
+		nil,
+		{WithChecksum(true)},
+		{WithTenant(7), WithShard(3), WithChecksum(true), WithUppercaseOutput()},
+		{WithGroupingDashes(8)},
+	}
+	buf := []byte("ids:")
+	for i, opts := range optSets {
+		want := a.New("order", opts...)
+		start := len(buf)
+		buf = b.AppendID(buf, "order", opts...)
+		if got := string(buf[start:]); got != want {
+			t.Fatalf("opts %d: AppendID = %s, New = %s", i, got, want)
+		}
+	}
+	if !strings.HasPrefix(string(buf), "ids:") {
+		t.Fatalf("AppendID clobbered dst: %q", buf)
+	}
+}
+
+func TestAppendIDDoesNotAllocate(t *testing.T) {
+	g := NewGenerator(WithEntropy(mrand.NewChaCha8([32]byte{})))
+	buf := make([]byte, 0, MaxLength)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = g.AppendID(buf[:0], "order")
+	})
+	if allocs != 0 {
+		t.Fatalf("AppendID allocated %.1f times per call", allocs)
+	}
+}
+
+func BenchmarkAppendID(b *testing.B) {
+	buf := make([]byte, 0, MaxLength)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = AppendID(buf[:0], "order")
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = New("order")
+	}
+}
+
+func TestWithMonotonicHoldsBackwardClock(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(
+		WithClock(steppingClock(base, base.Add(-time.Second), base.Add(-2*time.Second), base.Add(time.Millisecond))),
+		WithMonotonic(true),
+	)
+	var prev *Parsed
+	for i := 0; i < 4; i++ {
+		p := mustParse(t, g.New("order"))
+		if prev != nil && p.OrderKey() <= prev.OrderKey() {
+			t.Fatalf("call %d: %+v does not sort after %+v", i, p, prev)
+		}
+		prev = p
+	}
+	if prev.TimeMs != base.Add(time.Millisecond).UnixMilli() || prev.Seq != 0 {
+		t.Fatalf("clock catching up not followed: %+v", prev)
+	}
+	if n := g.ClockRegressions(); n != 0 {
+		t.Fatalf("ClockRegressions = %d, want 0", n)
+	}
+}
+
+func TestWithMonotonicOverflowBorrowsNextMs(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := []time.Time{base}
+	for i := 0; i < 4200; i++ {
+		ts = append(ts, base.Add(-time.Duration(i)*time.Microsecond-time.Millisecond))
+	}
+	g := NewGenerator(WithClock(steppingClock(ts...)), WithMonotonic(true))
+	prev := ""
+	for i := range ts {
+		id := g.New("order")
+		if id <= prev {
+			t.Fatalf("call %d: %s not above %s", i, id, prev)
+		}
+		prev = id
+	}
+	if p := mustParse(t, prev); p.TimeMs != base.UnixMilli()+1 {
+		t.Fatalf("last time = %d, want one borrowed ms past %d", p.TimeMs, base.UnixMilli())
+	}
+}
+
+func TestSequenceOverflowAdvancesMs(t *testing.T) {
+	frozen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(WithClock(fixedClock(frozen)))
+	const n = 5000
+	seen := make(map[string]bool, n)
+	prev := ""
+	for i := 0; i < n; i++ {
+		id := g.New("order")
+		if id <= prev {
+			t.Fatalf("call %d: %s not above %s", i, id, prev)
+		}
+		if seen[id] {
+			t.Fatalf("call %d: duplicate %s", i, id)
+		}
+		seen[id] = true
+		prev = id
+	}
+	p := mustParse(t, prev)
+	if p.TimeMs != frozen.UnixMilli()+1 || p.Seq != n-4096-1 {
+		t.Fatalf("last = time %d seq %d, want time %d seq %d", p.TimeMs, p.Seq, frozen.UnixMilli()+1, n-4096-1)
+	}
+	if n := g.ClockRegressions(); n != 0 {
+		t.Fatalf("ClockRegressions = %d, want 0", n)
+	}
+}
+
+func TestSequencePerStream(t *testing.T) {
+	g := NewGenerator(WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))))
+	next := map[string]uint16{}
+	for i := 0; i < 6; i++ {
+		for _, prefix := range []string{"order", "user"} {
+			p := mustParse(t, g.New(prefix))
+			if p.Seq != next[prefix] {
+				t.Fatalf("%s call %d: seq = %d, want %d", prefix, i, p.Seq, next[prefix])
+			}
+			next[prefix]++
+		}
+	}
+	if p := mustParse(t, g.New("order", WithTenant(1))); p.Seq != 0 {
+		t.Fatalf("new tenant seq = %d, want 0", p.Seq)
+	}
+	if p := mustParse(t, g.New("order", WithShard(1))); p.Seq != 0 {
+		t.Fatalf("new shard seq = %d, want 0", p.Seq)
+	}
+}
+
+func TestStalePerStreamStateIsPruned(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(WithClock(ClockFunc(func() time.Time { return now })))
+	for i := 0; i < minPruneAt; i++ {
+		g.New("order", WithTenant(uint16(i)))
+	}
+	now = now.Add(2 * time.Second)
+	g.New("user")
+	if n := len(g.streams); n != 1 {
+		t.Fatalf("streams = %d after pruning, want 1", n)
+	}
+	if p := mustParse(t, g.New("order")); p.Seq != 0 {
+		t.Fatalf("pruned stream seq = %d, want 0", p.Seq)
+	}
+}
+
+func TestWithEpochRejectsInvalidEpochs(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		epoch time.Time
+	}{
+		{name: "zero", epoch: time.Time{}},
+		{name: "future", epoch: now.Add(time.Hour)},
+		{name: "too far in the past", epoch: time.UnixMilli(now.UnixMilli() - 1<<48)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerator(WithEpoch(tt.epoch), WithClock(fixedClock(now)))
+			if _, err := g.NewE("order"); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("NewE error = %v, want ErrInvalidOption", err)
+			}
+			if _, err := g.newBatch("order", 2, nil); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("newBatch error = %v, want ErrInvalidOption", err)
+			}
+		})
+	}
+
+	// The last epoch whose range still covers now is accepted.
+	oldest := time.UnixMilli(now.UnixMilli() - (1<<48 - 1))
+	g := NewGenerator(WithEpoch(oldest), WithClock(fixedClock(now)))
+	id, err := g.NewE("order")
+	if err != nil {
+		t.Fatalf("NewE with the oldest epoch: %v", err)
+	}
+	if p, err := g.Parse(id); err != nil || !p.Time().Equal(now) {
+		t.Fatalf("Parse(%s) = %v, %v; want %v", id, p, err, now)
+	}
+}
+
+func TestWithEpochRoundTrip(t *testing.T) {
+	epoch := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(WithEpoch(epoch))
+	for _, at := range []time.Time{
+		time.Date(2010, 6, 1, 12, 0, 0, 0, time.UTC), // before the default epoch
+		time.Date(2025, 1, 1, 0, 0, 0, 123e6, time.UTC),
+	} {
+		id := g.New("order", WithTime(at), WithTenant(5), WithChecksum(true))
+		p, err := g.Parse(id)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", id, err)
+		}
+		if !p.Time().Equal(at) || p.Tenant != 5 {
+			t.Fatalf("Parse(%s) = %v tenant %d, want %v tenant 5", id, p.Time(), p.Tenant, at)
+		}
+
+		// The Parsed remembers the epoch, so re-encoding reproduces the ID.
+		if s, err := p.String(); err != nil || s != id {
+			t.Fatalf("String() = %q, %v; want %q", s, err, id)
+		}
+		q := mustParse(t, id)
+		if p.Bytes() != q.Bytes() || p.OrderKey() != q.OrderKey() || !bytes.Equal(p.SortKey(), q.SortKey()) {
+			t.Fatalf("Parsed %s encodes differently from package Parse", id)
+		}
+
+		// Decoding against the default epoch shifts the time.
+		shift := epoch2020 - epoch.UnixMilli()
+		if q.TimeMs != at.UnixMilli()+shift {
+			t.Fatalf("package Parse TimeMs = %d, want %d", q.TimeMs, at.UnixMilli()+shift)
+		}
+	}
+
+	// Times before the epoch clamp to it.
+	id := g.New("order", WithTime(epoch.Add(-time.Hour)))
+	if p, err := g.Parse(id); err != nil || !p.Time().Equal(epoch) {
+		t.Fatalf("Parse(%s) = %v, %v; want %v", id, p, err, epoch)
+	}
+
+	// IDs from the same Generator still sort by time.
+	a := g.New("order")
+	b := g.New("order")
+	if a >= b {
+		t.Fatalf("IDs out of order: %s >= %s", a, b)
+	}
+}
@@ -0,0 +1,4 @@
+// Package gormtest tests orderlyid.ID against GORM and SQLite. It is a
+// separate module so that the orderlyid module itself does not depend on GORM
+// or the cgo SQLite driver.
+package gormtest
@@ -0,0 +1,18 @@
+module github.com/orderlykit/orderlyid/gormtest
+
+go 1.23.1
+
+require (
+	github.com/orderlykit/orderlyid v0.0.0
+	gorm.io/driver/sqlite v1.6.0
+	gorm.io/gorm v1.31.2
+)
+
+require (
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
+replace github.com/orderlykit/orderlyid => ../
@@ -0,0 +1,102 @@
+package gormtest
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/orderlykit/orderlyid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type gormOrder struct {
+	ID       orderlyid.ID `gorm:"primaryKey"`
+	Customer orderlyid.ID
+	Referrer sql.Null[orderlyid.ID] `gorm:"type:varchar(73)"`
+	Note     string
+}
+
+func TestIDGormRoundTrip(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.AutoMigrate(&gormOrder{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	want := gormOrder{
+		ID:       orderlyid.ID(orderlyid.New("order", orderlyid.WithChecksum(true))),
+		Customer: orderlyid.ID(orderlyid.New("cust")),
+		Note:     "first",
+	}
+	if err := db.Create(&want).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var got gormOrder
+	if err := db.First(&got, "id = ?", want.ID).Error; err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if got != want {
+		t.Fatalf("round trip = %+v, want %+v", got, want)
+	}
+
+	// Nullable columns use sql.Null[orderlyid.ID]. The empty ID is not
+	// written as NULL, and NULL does not scan into an ID.
+	ref := gormOrder{
+		ID:       orderlyid.ID(orderlyid.New("order")),
+		Customer: orderlyid.ID(orderlyid.New("cust")),
+		Referrer: sql.Null[orderlyid.ID]{V: want.Customer, Valid: true},
+	}
+	if err := db.Create(&ref).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var gotRef gormOrder
+	if err := db.First(&gotRef, "id = ?", ref.ID).Error; err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if gotRef != ref {
+		t.Fatalf("round trip = %+v, want %+v", gotRef, ref)
+	}
+	empty := orderlyid.New("order")
+	if err := db.Create(&gormOrder{ID: orderlyid.ID(empty)}).Error; !errors.Is(err, orderlyid.ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat writing an empty ID, got %v", err)
+	}
+	if err := db.Exec("INSERT INTO gorm_orders (id) VALUES (?)", empty).Error; err != nil {
+		t.Fatalf("raw insert: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("DB: %v", err)
+	}
+	var customer orderlyid.ID
+	if err := sqlDB.QueryRow("SELECT customer FROM gorm_orders WHERE id = ?", empty).Scan(&customer); !errors.Is(err, orderlyid.ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat scanning NULL, got %v", err)
+	}
+	if err := db.Delete(&gormOrder{}, "id = ?", empty).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// Invalid IDs are rejected on write and on read.
+	if err := db.Create(&gormOrder{ID: "order_123"}).Error; !errors.Is(err, orderlyid.ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength on create, got %v", err)
+	}
+	if err := db.Exec("INSERT INTO gorm_orders (id, customer) VALUES (?, ?)", orderlyid.New("order"), "bogus").Error; err != nil {
+		t.Fatalf("raw insert: %v", err)
+	}
+	var rows []gormOrder
+	if err := db.Find(&rows).Error; !errors.Is(err, orderlyid.ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat on scan, got %v", err)
+	}
+
+	var colType string
+	if err := db.Raw("SELECT type FROM pragma_table_info('gorm_orders') WHERE name = 'customer'").Scan(&colType).Error; err != nil {
+		t.Fatalf("table info: %v", err)
+	}
+	if !strings.EqualFold(colType, "varchar(73)") {
+		t.Fatalf("column type = %q, want varchar(73)", colType)
+	}
+}
@@ -0,0 +1,32 @@
+package orderlyid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseGrouped parses an ID that may contain display dashes, as produced by
+// WithGroupingDashes, and returns its canonical dash-free form together with
+// the decoded fields.
+//
+// All dashes after the prefix separator are removed. A remaining tail of 36,
+// 38, or 40 characters is read as a 32-character payload followed by a 4-, 6-,
+// or 8-character checksum; a tail of 32 characters has no checksum. Canonical IDs are
+// accepted unchanged. ParseGrouped may return any error returned by Parse.
+func ParseGrouped(s string) (string, *Parsed, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, '_')
+	if i <= 0 {
+		return "", nil, fmt.Errorf("%w: missing prefix separator", ErrInvalidFormat)
+	}
+	rest := strings.ReplaceAll(s[i+1:], "-", "")
+	canonical := s[:i+1] + rest
+	if validChecksumLen(len(rest) - 32) {
+		canonical = s[:i+1] + rest[:32] + "-" + rest[32:]
+	}
+	p, err := Parse(canonical)
+	if err != nil {
+		return "", nil, err
+	}
+	return canonical, p, nil
+}
@@ -0,0 +1,83 @@
+package orderlyid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGroupingDashesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		checksum bool
+		upper    bool
+	}{
+		{name: "groups of 4", n: 4},
+		{name: "groups of 5 with checksum", n: 5, checksum: true},
+		{name: "groups of 8 uppercase", n: 8, checksum: true, upper: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithTenant(5), WithGroupingDashes(tt.n), WithChecksum(tt.checksum)}
+			if tt.upper {
+				opts = append(opts, WithUppercaseOutput())
+			}
+			grouped := New("order", opts...)
+			groups := strings.Split(strings.TrimPrefix(grouped, "order_"), "-")
+			wantGroups := (32 + tt.n - 1) / tt.n
+			if tt.checksum {
+				wantGroups++
+			}
+			if len(groups) != wantGroups {
+				t.Fatalf("%s: %d groups, want %d", grouped, len(groups), wantGroups)
+			}
+			if len(groups[0]) != tt.n {
+				t.Fatalf("%s: first group has %d chars, want %d", grouped, len(groups[0]), tt.n)
+			}
+			if _, err := Parse(grouped); err == nil {
+				t.Fatalf("Parse accepted grouped form %s", grouped)
+			}
+
+			canonical, p, err := ParseGrouped(grouped)
+			if err != nil {
+				t.Fatalf("ParseGrouped(%s): %v", grouped, err)
+			}
+			if p.Tenant != 5 || p.HasChecksum != tt.checksum {
+				t.Fatalf("unexpected parse result: %+v", p)
+			}
+			wantLen := len("order_") + 32
+			if tt.checksum {
+				wantLen += 5
+			}
+			if len(canonical) != wantLen {
+				t.Fatalf("canonical %q has length %d, want %d", canonical, len(canonical), wantLen)
+			}
+			if _, err := Parse(canonical); err != nil {
+				t.Fatalf("canonical form does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseGroupedLenient(t *testing.T) {
+	id := New("order", WithChecksum(true))
+	canonical, _, err := ParseGrouped(id)
+	if err != nil || canonical != id {
+		t.Fatalf("ParseGrouped(canonical) = %q, %v; want %q", canonical, err, id)
+	}
+
+	// Arbitrary dash placement is tolerated.
+	messy := "order_" + strings.Join(strings.Split(strings.ReplaceAll(id[len("order_"):], "-", ""), ""), "-")
+	canonical, _, err = ParseGrouped(messy)
+	if err != nil || canonical != id {
+		t.Fatalf("ParseGrouped(%q) = %q, %v; want %q", messy, canonical, err, id)
+	}
+
+	if _, _, err := ParseGrouped("order_0000-0000"); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+	if _, _, err := ParseGrouped("order"); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
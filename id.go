@@ -0,0 +1,147 @@
+package orderlyid
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+)
+
+// MaxLength is the length of the longest valid canonical ID: a 31-character
+// prefix, the separator, the 32-character payload, and a "-" plus the longest,
+// 8-character checksum. The payload and a default 4-character checksum alone
+// take 37 characters.
+const MaxLength = 31 + 1 + 32 + 1 + 8
+
+// ID is an OrderlyID in its textual form. Using ID rather than string in
+// signatures documents intent, and its methods avoid repeated re-parsing at
+// call sites.
+//
+// ID implements sql.Scanner and driver.Valuer, validating the ID in both
+// directions, and GORM's GormDataType so that a model field of type ID
+// migrates to a varchar column sized for any valid ID. Neither the empty ID
+// nor NULL is accepted; use sql.Null[ID] for nullable columns. It also
+// implements slog.LogValuer, logging its decoded components.
+type ID string
+
+// NewID is like New but returns the generated ID as an ID.
+func NewID(prefix string, opts ...Option) ID {
+	return ID(New(prefix, opts...))
+}
+
+// String returns the ID in its textual form.
+func (id ID) String() string {
+	return string(id)
+}
+
+// Parse decodes the ID. It is equivalent to Parse(string(id)).
+func (id ID) Parse() (*Parsed, error) {
+	return Parse(string(id))
+}
+
+// Valid reports whether the ID parses.
+func (id ID) Valid() bool {
+	return IsValid(string(id))
+}
+
+// Prefix returns the prefix of the ID, or "" if the ID is not valid.
+func (id ID) Prefix() string {
+	var p Parsed
+	if ParseInto(string(id), &p) != nil {
+		return ""
+	}
+	return p.Prefix
+}
+
+// GormDataType returns the column type used by GORM migrations.
+func (ID) GormDataType() string {
+	return fmt.Sprintf("varchar(%d)", MaxLength)
+}
+
+// Value implements driver.Valuer. It returns an error wrapping
+// ErrInvalidFormat for the empty ID, which would otherwise be written as a
+// NULL that Scan rejects, and the Parse error if the ID does not parse. Write
+// nullable columns from sql.Null[ID].
+func (id ID) Value() (driver.Value, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: empty ID", ErrInvalidFormat)
+	}
+	if _, err := Parse(string(id)); err != nil {
+		return nil, err
+	}
+	return string(id), nil
+}
+
+// Scan implements sql.Scanner for text columns. It returns an error wrapping
+// ErrInvalidFormat for NULL and for values that are not text, and the Parse
+// error if the stored value does not parse. Use sql.Null[ID] for nullable
+// columns.
+func (id *ID) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		return fmt.Errorf("%w: cannot scan NULL into ID", ErrInvalidFormat)
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into ID", ErrInvalidFormat, src)
+	}
+	if _, err := Parse(s); err != nil {
+		return err
+	}
+	*id = ID(s)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the canonical
+// form of the ID as produced by Normalize with ChecksumKeep, or an error if
+// the ID does not parse. The empty ID marshals to empty text.
+func (id ID) MarshalText() ([]byte, error) {
+	if id == "" {
+		return []byte{}, nil
+	}
+	s, err := Normalize(string(id), ChecksumKeep)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text yields the
+// empty ID; any other input must parse, and UnmarshalText returns the Parse
+// error otherwise.
+func (id *ID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*id = ""
+		return nil
+	}
+	s := string(text)
+	if _, err := Parse(s); err != nil {
+		return err
+	}
+	*id = ID(s)
+	return nil
+}
+
+// LogValue implements slog.LogValuer. A valid ID logs as a group holding the
+// ID itself and its prefix, time, tenant, shard, and seq components. An ID
+// that does not parse logs as a group holding the raw string and the Parse
+// error, so logging never fails.
+func (id ID) LogValue() slog.Value {
+	var p Parsed
+	if err := ParseInto(string(id), &p); err != nil {
+		return slog.GroupValue(
+			slog.String("id", string(id)),
+			slog.String("error", err.Error()),
+		)
+	}
+	return slog.GroupValue(
+		slog.String("id", string(id)),
+		slog.String("prefix", p.Prefix),
+		slog.Time("time", p.Time()),
+		slog.Uint64("tenant", uint64(p.Tenant)),
+		slog.Uint64("shard", uint64(p.Shard)),
+		slog.Uint64("seq", uint64(p.Seq)),
+	)
+}
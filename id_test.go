@@ -0,0 +1,252 @@
+package orderlyid
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIDScan(t *testing.T) {
+	valid := New("order", WithChecksum(true))
+	tests := []struct {
+		name    string
+		src     driver.Value
+		want    ID
+		wantErr error
+	}{
+		{name: "string", src: valid, want: ID(valid)},
+		{name: "bytes", src: []byte(valid), want: ID(valid)},
+		{name: "null", src: nil, wantErr: ErrInvalidFormat},
+		{name: "invalid string", src: "order_123", wantErr: ErrInvalidPayloadLength},
+		{name: "invalid bytes", src: []byte("Order_123"), wantErr: ErrInvalidPrefix},
+		{name: "int64", src: int64(42), wantErr: ErrInvalidFormat},
+		{name: "float64", src: 4.2, wantErr: ErrInvalidFormat},
+		{name: "bool", src: true, wantErr: ErrInvalidFormat},
+		{name: "time", src: time.Unix(0, 0), wantErr: ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := ID("stale")
+			err := id.Scan(tt.src)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if id != "stale" {
+					t.Fatalf("failed Scan modified id to %q", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if id != tt.want {
+				t.Fatalf("id = %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
+
+func TestIDValue(t *testing.T) {
+	valid := New("order")
+	v, err := ID(valid).Value()
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+	if s, ok := v.(string); !ok || s != valid {
+		t.Fatalf("Value = %#v, want %q", v, valid)
+	}
+	if _, err := ID("").Value(); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("Value of empty ID: expected ErrInvalidFormat, got %v", err)
+	}
+	if _, err := ID("order_123").Value(); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	id := New(strings.Repeat("a", 31), WithChecksumLen(8))
+	if len(id) != MaxLength {
+		t.Fatalf("len = %d, want %d", len(id), MaxLength)
+	}
+}
+
+func TestIDMethods(t *testing.T) {
+	id := NewID("order", WithTenant(9), WithChecksum(true))
+	if !id.Valid() {
+		t.Fatalf("Valid(%s) = false", id)
+	}
+	if got := id.Prefix(); got != "order" {
+		t.Fatalf("Prefix = %q, want order", got)
+	}
+	if got := id.String(); got != string(id) {
+		t.Fatalf("String = %q, want %q", got, id)
+	}
+	p, err := id.Parse()
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Tenant != 9 || !p.HasChecksum {
+		t.Fatalf("unexpected parse result: %+v", p)
+	}
+
+	bad := ID("order_123")
+	if bad.Valid() {
+		t.Fatalf("Valid(%s) = true", bad)
+	}
+	if got := bad.Prefix(); got != "" {
+		t.Fatalf("Prefix of invalid ID = %q, want empty", got)
+	}
+	if _, err := bad.Parse(); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+}
+
+func TestIDJSONRoundTrip(t *testing.T) {
+	type order struct {
+		ID       ID `json:"id"`
+		Customer ID `json:"customer,omitempty"`
+	}
+	in := order{ID: NewID("order", WithChecksum(true))}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"id":"` + string(in.ID) + `"}`; string(b) != want {
+		t.Fatalf("Marshal = %s, want %s", b, want)
+	}
+	var out order
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out != in {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+
+	// Uppercase input is accepted and marshals back in canonical form.
+	upper := ID("order_" + strings.ToUpper(string(in.ID[len("order_"):])))
+	b, err = json.Marshal(order{ID: upper})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"id":"` + string(in.ID) + `"}`; string(b) != want {
+		t.Fatalf("Marshal = %s, want %s", b, want)
+	}
+}
+
+func TestIDUnmarshalText(t *testing.T) {
+	valid := New("order")
+	tests := []struct {
+		name    string
+		in      string
+		want    ID
+		wantErr error
+	}{
+		{name: "empty", in: `""`, want: ""},
+		{name: "valid", in: `"` + valid + `"`, want: ID(valid)},
+		{name: "bad prefix", in: `"Order_` + valid[len("order_"):] + `"`, wantErr: ErrInvalidPrefix},
+		{name: "short payload", in: `"order_123"`, wantErr: ErrInvalidPayloadLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := ID("stale")
+			err := json.Unmarshal([]byte(tt.in), &id)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if id != tt.want {
+				t.Fatalf("id = %q, want %q", id, tt.want)
+			}
+		})
+	}
+
+	if _, err := ID("order_123").MarshalText(); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+}
+
+// recordHandler captures the last record logged through it.
+type recordHandler struct{ rec *slog.Record }
+
+func (h recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h recordHandler) Handle(_ context.Context, r slog.Record) error {
+	*h.rec = r.Clone()
+	return nil
+}
+func (h recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h recordHandler) WithGroup(string) slog.Handler      { return h }
+
+// loggedGroup logs id under the key "id" and returns the resolved group
+// attributes by key.
+func loggedGroup(t *testing.T, id ID) map[string]slog.Value {
+	t.Helper()
+	var rec slog.Record
+	slog.New(recordHandler{&rec}).Info("created", "id", id)
+	got := map[string]slog.Value{}
+	rec.Attrs(func(a slog.Attr) bool {
+		if a.Key != "id" {
+			t.Fatalf("unexpected attribute %q", a.Key)
+		}
+		v := a.Value.Resolve()
+		if v.Kind() != slog.KindGroup {
+			t.Fatalf("id kind = %v, want group", v.Kind())
+		}
+		for _, ga := range v.Group() {
+			got[ga.Key] = ga.Value
+		}
+		return true
+	})
+	return got
+}
+
+func TestIDLogValue(t *testing.T) {
+	at := time.UnixMilli(1735689600123).UTC()
+	id := NewID("order", WithTenant(7), WithShard(300), WithTime(at), WithChecksum(true))
+	p, err := id.Parse()
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := loggedGroup(t, id)
+	if v := got["id"]; v.String() != string(id) {
+		t.Fatalf("id = %v, want %s", v, id)
+	}
+	if v := got["prefix"]; v.String() != "order" {
+		t.Fatalf("prefix = %v, want order", v)
+	}
+	if v := got["time"]; v.Kind() != slog.KindTime || !v.Time().Equal(at) {
+		t.Fatalf("time = %v, want %v", v, at)
+	}
+	for key, want := range map[string]uint64{"tenant": 7, "shard": 300, "seq": uint64(p.Seq)} {
+		if v := got[key]; v.Kind() != slog.KindUint64 || v.Uint64() != want {
+			t.Fatalf("%s = %v, want %d", key, v, want)
+		}
+	}
+	if _, ok := got["error"]; ok {
+		t.Fatalf("valid ID logged an error: %v", got["error"])
+	}
+}
+
+func TestIDLogValueInvalid(t *testing.T) {
+	got := loggedGroup(t, ID("order_123"))
+	if v := got["id"]; v.String() != "order_123" {
+		t.Fatalf("id = %v, want order_123", v)
+	}
+	_, want := Parse("order_123")
+	if v := got["error"]; v.String() != want.Error() {
+		t.Fatalf("error = %v, want %v", v, want)
+	}
+	if _, ok := got["prefix"]; ok {
+		t.Fatalf("invalid ID logged components: %v", got)
+	}
+}
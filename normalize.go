@@ -0,0 +1,47 @@
+package orderlyid
+
+// ChecksumPolicy selects how Normalize treats the checksum suffix.
+type ChecksumPolicy int
+
+const (
+	// ChecksumKeep emits a checksum only if the input carried one.
+	ChecksumKeep ChecksumPolicy = iota
+	// ChecksumStrip always emits the ID without a checksum.
+	ChecksumStrip
+	// ChecksumRequire always emits the ID with a checksum, computing one if
+	// the input had none.
+	ChecksumRequire
+)
+
+// Normalize returns the canonical storage form of s: surrounding whitespace
+// removed, payload and checksum lowercased, and the aliases I, L, O, and U
+// resolved to their canonical symbols. The checksum suffix is kept, stripped,
+// or added according to policy; a kept checksum keeps its length, and an
+// added one has 4 characters. A checksum present in the input is always
+// verified, whatever the policy.
+//
+// Normalize may return any error returned by Parse.
+func Normalize(s string, policy ChecksumPolicy) (string, error) {
+	p, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	n := p.ChecksumLen
+	switch policy {
+	case ChecksumStrip:
+		n = 0
+	case ChecksumRequire:
+		n = max(n, 4)
+	}
+	return formatBody(p.Prefix, p.Bytes(), n), nil
+}
+
+// Canonicalize rewrites any ID accepted by Parse into its single canonical
+// form, recomputing the checksum if s carried one. It is equivalent to
+// Normalize(s, ChecksumKeep). The result is accepted by ParseStrict, and
+// canonicalizing it again returns it unchanged.
+//
+// Canonicalize may return any error returned by Parse.
+func Canonicalize(s string) (string, error) {
+	return Normalize(s, ChecksumKeep)
+}
@@ -0,0 +1,98 @@
+package orderlyid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	withCS, err := NewFromParts(Components{Prefix: "order", TimeMs: 1735689600000, Random60: 0x0123456789ABCDEF}, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	plain := baseOf(withCS)
+	upper := "order_" + strings.ToUpper(withCS[len("order_"):])
+	// The payload of a 2025 timestamp starts with "00", so "Oo" are aliases.
+	glyphs := "order_Oo" + plain[len("order_00"):]
+
+	tests := []struct {
+		name   string
+		in     string
+		policy ChecksumPolicy
+		want   string
+	}{
+		{name: "keep checksum", in: withCS, policy: ChecksumKeep, want: withCS},
+		{name: "keep none", in: plain, policy: ChecksumKeep, want: plain},
+		{name: "strip", in: withCS, policy: ChecksumStrip, want: plain},
+		{name: "require adds", in: plain, policy: ChecksumRequire, want: withCS},
+		{name: "require keeps", in: withCS, policy: ChecksumRequire, want: withCS},
+		{name: "mixed case", in: upper, policy: ChecksumKeep, want: withCS},
+		{name: "ambiguous glyphs", in: glyphs, policy: ChecksumRequire, want: withCS},
+		{name: "whitespace", in: "  " + plain + "\n", policy: ChecksumStrip, want: plain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.in, tt.policy)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Normalize(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	bad := withCS[:len(withCS)-1] + "0"
+	if strings.HasSuffix(withCS, "0") {
+		bad = withCS[:len(withCS)-1] + "1"
+	}
+	if _, err := Normalize(bad, ChecksumStrip); !errors.Is(err, ErrInvalidChecksum) {
+		t.Fatalf("expected ErrInvalidChecksum, got %v", err)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	withCS, err := NewFromParts(Components{Prefix: "order", TimeMs: 1735689600000, Random60: 0x0123456789ABCDEF}, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	plain := baseOf(withCS)
+	payload := plain[len("order_"):]
+	aliases := strings.NewReplacer("0", "O", "1", "I")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "canonical", in: withCS, want: withCS},
+		{name: "uppercase payload", in: "order_" + strings.ToUpper(payload), want: plain},
+		{name: "uppercase checksum", in: plain + "-" + strings.ToUpper(withCS[len(plain)+1:]), want: withCS},
+		{name: "aliases", in: "order_" + aliases.Replace(payload), want: plain},
+		{name: "lowercase aliases", in: "order_" + strings.ToLower(aliases.Replace(payload)) + withCS[len(plain):], want: withCS},
+		{name: "whitespace", in: "\t" + plain + " ", want: plain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize(tt.in)
+			if err != nil {
+				t.Fatalf("Canonicalize(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Canonicalize(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+			if again, err := Canonicalize(got); err != nil || again != got {
+				t.Fatalf("Canonicalize not idempotent: %q, %v", again, err)
+			}
+			if _, err := ParseStrict(got); err != nil {
+				t.Fatalf("ParseStrict(%s): %v", got, err)
+			}
+		})
+	}
+
+	// Prefixes are case-sensitive, so an uppercase prefix is not accepted.
+	if _, err := Canonicalize("ORDER_" + payload); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package orderlyid
+
+// openAPIPattern matches canonical IDs: a prefix, "_", 32 lowercase Crockford
+// Base32 symbols, and an optional "-" plus 4-, 6-, or 8-symbol checksum.
+const openAPIPattern = `^[a-z][a-z0-9]{1,30}_[0-9a-hjkmnp-tv-z]{32}(-([0-9a-hjkmnp-tv-z]{4}|[0-9a-hjkmnp-tv-z]{6}|[0-9a-hjkmnp-tv-z]{8}))?$`
+
+// OpenAPIFormat returns the format name and validation pattern to use for
+// OrderlyID string fields in JSON Schema and OpenAPI documents:
+//
+//	type: string
+//	format: orderlyid
+//	pattern: ^[a-z][a-z0-9]{1,30}_...$
+//
+// The pattern accepts exactly the canonical lowercase form produced by New,
+// with any checksum length selected by WithChecksumLen. Parse is more lenient
+// (uppercase, the aliases I, L, O, and U) and also verifies the checksum,
+// which a pattern cannot. Both values are stable.
+func OpenAPIFormat() (name string, pattern string) {
+	return "orderlyid", openAPIPattern
+}
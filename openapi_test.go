@@ -0,0 +1,60 @@
+package orderlyid
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIFormatPattern(t *testing.T) {
+	name, pattern := OpenAPIFormat()
+	if name != "orderlyid" {
+		t.Fatalf("name = %q, want orderlyid", name)
+	}
+	re := regexp.MustCompile(pattern)
+
+	valid := []string{
+		New("order"),
+		New("order", WithChecksum(true)),
+		New("order", WithChecksumLen(4)),
+		New("order", WithChecksumLen(6)),
+		New("order", WithChecksumLen(8)),
+		New(strings.Repeat("z", 31), WithChecksum(true)),
+		"ab_00000000000000000000000000000000",
+	}
+	for _, id := range valid {
+		if !re.MatchString(id) {
+			t.Fatalf("pattern rejects valid id %s", id)
+		}
+	}
+
+	id := New("order", WithChecksum(true))
+	invalid := []string{
+		"",
+		"order",
+		"a_00000000000000000000000000000000",
+		"Order_00000000000000000000000000000000",
+		"order_0000000000000000000000000000000",
+		"order_000000000000000000000000000000000",
+		"order_0000000000000000000000000000000u",
+		"order_" + strings.ToUpper(id[len("order_"):]),
+		id + "0",
+		id + "000",
+		id + "00000",
+		New("order", WithChecksumLen(8)) + "0",
+		" " + id,
+		New(strings.Repeat("z", 32)[:31]) + "-abc",
+	}
+	for _, s := range invalid {
+		if re.MatchString(s) {
+			t.Fatalf("pattern accepts invalid id %q", s)
+		}
+	}
+
+	// Everything New emits in canonical form parses.
+	for _, s := range valid {
+		if _, err := Parse(s); err != nil {
+			t.Fatalf("valid id %s does not parse: %v", s, err)
+		}
+	}
+}
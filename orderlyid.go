@@ -1,26 +1,102 @@
 package orderlyid
 
 import (
-	"crypto/rand"
-	"encoding/binary"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
-	"sync"
 	"time"
 )
 
 type options struct {
-	tenant        uint16
-	shard         uint16
-	withChecksum  bool
-	bucketSeconds int
+	tenant            uint16
+	shard             uint16
+	shardRing         uint16
+	hasShardRing      bool
+	withChecksum      bool
+	checksumLen       int // 0 means 4
+	bucketSeconds     int
+	requireRegistered bool
+	minPrefixLen      int
+	maxPrefixLen      int
+	noMonotonic       bool
+	uppercase         bool
+	groupSize         int
+	strict            bool
+	expectedPrefix    string
+	notBefore         int64 // Unix ms; 0 means unbounded
+	notAfter          int64 // Unix ms; 0 means unbounded
+	allowFuture       bool
+	region            uint8
+	hasRegion         bool
+	confusableCheck   bool
+	at                time.Time
+	hasTime           bool
+	flags             uint8 // application-defined bits for WithFlags
+	entropy           io.Reader
+	alphabet          *Alphabet // set from the Generator; nil means Crockford
 }
 
-// Option configures ID generation in New.
+// Option configures ID generation in New and validation in ParseWith.
+// Options that only affect generation are ignored by ParseWith.
 type Option func(*options)
 
+func buildOptions(opts []Option) options {
+	if len(opts) == 0 {
+		return options{}
+	}
+	o := new(options)
+	for _, fn := range opts {
+		fn(o)
+	}
+	return *o
+}
+
+// validate reports option combinations that cannot produce or accept
+// parseable IDs. Options that could make output ambiguous, such as a custom
+// separator or alphabet, must be checked here.
+func (o *options) validate() error {
+	switch {
+	case o.minPrefixLen > 0 && o.maxPrefixLen > 0 && o.minPrefixLen > o.maxPrefixLen:
+		return fmt.Errorf("%w: minimum prefix length %d exceeds maximum %d", ErrInvalidOption, o.minPrefixLen, o.maxPrefixLen)
+	case o.maxPrefixLen < 0 || o.maxPrefixLen == 1:
+		return fmt.Errorf("%w: maximum prefix length %d is below the 2-char minimum", ErrInvalidOption, o.maxPrefixLen)
+	case o.minPrefixLen > 31:
+		return fmt.Errorf("%w: minimum prefix length %d exceeds the 31-char maximum", ErrInvalidOption, o.minPrefixLen)
+	case o.bucketSeconds < 0:
+		return fmt.Errorf("%w: negative bucket size %d", ErrInvalidOption, o.bucketSeconds)
+	case o.hasShardRing && o.shardRing == 0:
+		return fmt.Errorf("%w: shard ring size must be positive", ErrInvalidOption)
+	case o.hasRegion && o.region > 0x0F:
+		return fmt.Errorf("%w: region %d does not fit in 4 bits", ErrInvalidOption, o.region)
+	case o.notBefore != 0 && o.notAfter != 0 && o.notBefore > o.notAfter:
+		return fmt.Errorf("%w: time bounds are inverted", ErrInvalidOption)
+	case o.expectedPrefix != "" && !prefixRe.MatchString(o.expectedPrefix):
+		return fmt.Errorf("%w: expected prefix %q is not a valid prefix", ErrInvalidOption, o.expectedPrefix)
+	case o.flags&^FlagApplicationMask != 0:
+		return fmt.Errorf("%w: flags 0x%02x touch bits outside FlagApplicationMask", ErrInvalidOption, o.flags)
+	case o.checksumLen != 0 && !validChecksumLen(o.checksumLen):
+		return fmt.Errorf("%w: checksum length %d is not 4, 6, or 8", ErrInvalidOption, o.checksumLen)
+	case o.alphabet != nil && o.uppercase:
+		return fmt.Errorf("%w: uppercase output requires the Crockford alphabet", ErrInvalidOption)
+	}
+	return nil
+}
+
+// WithFlags ORs mask into the flags byte of generated IDs, where Parse
+// reports it in Parsed.Flags. Only the application-defined bits 3..0
+// (FlagApplicationMask) are free: bits 7..6 carry the wire version, bit 5 is
+// FlagPrivacy, and bit 4 is FlagRegion, all set by the package itself. A mask
+// touching any of those bits makes New panic and NewE return an error
+// wrapping ErrInvalidOption. Repeated WithFlags options accumulate.
+func WithFlags(mask uint8) Option {
+	return func(o *options) {
+		o.flags |= mask
+	}
+}
+
 // WithTenant sets the 16-bit tenant value embedded in generated IDs.
 func WithTenant(t uint16) Option {
 	return func(o *options) {
@@ -38,14 +114,56 @@ func WithShard(s uint16) Option {
 // WithShardFromBytes hashes b into a deterministic 16-bit shard value.
 func WithShardFromBytes(b []byte) Option {
 	return func(o *options) {
-		var h uint32
-		for _, by := range b {
-			h = (h * 16777619) ^ uint32(by) // FNV-ish
+		o.shard = uint16(shardHash(b) & 0xFFFF)
+	}
+}
+
+// WithShardFromBytesUsing is like WithShardFromBytes but hashes b with h,
+// such as crc32.ChecksumIEEE or a wrapper around hash/fnv or xxhash. The
+// 32-bit result is folded to 16 bits by XORing its halves, so hashes whose
+// low bits are weak still spread across the shard space. A nil h uses the
+// WithShardFromBytes hash.
+func WithShardFromBytesUsing(b []byte, h func([]byte) uint32) Option {
+	if h == nil {
+		return WithShardFromBytes(b)
+	}
+	return func(o *options) {
+		v := h(b)
+		o.shard = uint16(v ^ v>>16)
+	}
+}
+
+// WithShardModulo hashes key with 32-bit FNV-1a and stores the hash modulo
+// ring as the shard, so the shard field can be used directly as a partition
+// index in [0, ring). The mapping depends only on key and ring; changing ring
+// moves most keys to a different shard. A ring of 0 makes New panic and NewE
+// return an error wrapping ErrInvalidOption.
+func WithShardModulo(key []byte, ring uint16) Option {
+	return func(o *options) {
+		o.shardRing, o.hasShardRing = ring, true
+		if ring == 0 {
+			return
+		}
+		h := uint32(2166136261)
+		for _, by := range key {
+			h ^= uint32(by)
+			h *= 16777619
 		}
-		o.shard = uint16(h & 0xFFFF)
+		o.shard = uint16(h % uint32(ring))
 	}
 }
 
+// shardHash is the multiplicative hash used by WithShardFromBytes. Its
+// output is part of the shard values already stored in IDs, so it must not
+// change.
+func shardHash(b []byte) uint32 {
+	var h uint32
+	for _, by := range b {
+		h = (h * 16777619) ^ uint32(by) // FNV-ish
+	}
+	return h
+}
+
 // WithChecksum enables or disables the trailing 4-character checksum.
 func WithChecksum(v bool) Option {
 	return func(o *options) {
@@ -53,6 +171,39 @@ func WithChecksum(v bool) Option {
 	}
 }
 
+// WithChecksumLen enables the trailing checksum with n characters: 4 (the
+// default, 20 bits), 6 (30 bits), or 8 (40 bits). Parse infers the length
+// from the suffix, so IDs with different checksum lengths can be mixed.
+//
+// Longer checksums trade 2 or 4 extra characters per ID for stronger tamper
+// and typo detection. The 4-character checksum never detects a change
+// confined to the last two payload characters, the low 10 bits of the random
+// field; a random corruption elsewhere slips past it with probability about
+// 1 in 10^6. The 6- and 8-character checksums cover the whole payload, and a
+// random corruption slips past them with probability about 1 in 10^9 and 1 in
+// 10^12. The 6-character checksum is a full Bech32 checksum, which also
+// detects every error confined to at most four characters. Other lengths
+// make New panic and NewE return an error wrapping ErrInvalidOption. A later
+// WithChecksum(false) disables the checksum again.
+func WithChecksumLen(n int) Option {
+	return func(o *options) {
+		o.withChecksum = true
+		o.checksumLen = n
+	}
+}
+
+// checksumLenOf returns the checksum length selected by o, or 0 if o has no
+// checksum.
+func (o *options) checksumLenOf() int {
+	switch {
+	case !o.withChecksum:
+		return 0
+	case o.checksumLen == 0:
+		return 4
+	}
+	return o.checksumLen
+}
+
 // WithBucketSeconds rounds the embedded timestamp down to sec-second buckets.
 func WithBucketSeconds(sec int) Option {
 	return func(o *options) {
@@ -60,6 +211,164 @@ func WithBucketSeconds(sec int) Option {
 	}
 }
 
+// WithRequireRegistered makes New and ParseWith reject prefixes that have not
+// been added with RegisterPrefix.
+func WithRequireRegistered() Option {
+	return func(o *options) {
+		o.requireRegistered = true
+	}
+}
+
+// WithConfusableCheck makes New and ParseWith reject prefixes that are not
+// registered but look like a registered prefix, such as "0rder" or "orcler"
+// when "order" is registered. Confusable characters are judged by a small
+// table of look-alikes within [a-z0-9], for example 0/o, 1/l/i, and rn/m.
+func WithConfusableCheck() Option {
+	return func(o *options) {
+		o.confusableCheck = true
+	}
+}
+
+// WithMaxPrefixLength makes New and ParseWith reject prefixes longer than n
+// characters. It can only tighten the built-in limit of 31 characters.
+func WithMaxPrefixLength(n int) Option {
+	return func(o *options) {
+		o.maxPrefixLen = n
+	}
+}
+
+// WithMinPrefixLength makes New and ParseWith reject prefixes shorter than n
+// characters. It can only tighten the built-in minimum of 2 characters.
+func WithMinPrefixLength(n int) Option {
+	return func(o *options) {
+		o.minPrefixLen = n
+	}
+}
+
+// WithMonotonicDisabled makes New skip the shared per-millisecond sequence
+// counter. The sequence field is always 0, so IDs minted in the same
+// millisecond are no longer ordered among themselves and uniqueness rests on
+// the 60-bit random field alone: about 1.5 million IDs in a single
+// millisecond (with the same prefix, tenant, and shard) give a one-in-a-million
+// chance of a collision.
+//
+// In this mode the Generator's entropy source is read without holding its
+// lock, so it must be safe for concurrent use; crypto/rand is.
+func WithMonotonicDisabled() Option {
+	return func(o *options) {
+		o.noMonotonic = true
+	}
+}
+
+// WithUppercaseOutput makes New emit the payload and checksum in uppercase for
+// display. The prefix stays lowercase. Parse accepts both cases, so uppercase
+// IDs decode to the same fields.
+func WithUppercaseOutput() Option {
+	return func(o *options) {
+		o.uppercase = true
+	}
+}
+
+// WithGroupingDashes makes New insert a dash after every n payload characters
+// for display, e.g. "order_00jc-1gmm-...". A checksum, if enabled, follows as
+// one more dash-separated group. Grouped IDs are not canonical: Parse rejects
+// them, so convert with ParseGrouped before storing. n <= 0 disables grouping.
+func WithGroupingDashes(n int) Option {
+	return func(o *options) {
+		o.groupSize = n
+	}
+}
+
+// WithRegion stamps the region identifier r into generated IDs so that IDs
+// minted in different regions never collide, even in the same millisecond.
+//
+// The region takes the top 4 bits of the shard field and sets FlagRegion,
+// which allows 16 regions (0-15) and leaves 12 shard bits for WithShard.
+// Larger values are rejected with ErrInvalidOption. Parse reports the region
+// in Parsed.Region.
+func WithRegion(r uint8) Option {
+	return func(o *options) {
+		o.region = r
+		o.hasRegion = true
+	}
+}
+
+// WithTime makes New embed t instead of reading the Generator's clock, for
+// example to backfill historical records. WithBucketSeconds and
+// WithPrivacyLevel apply to t. Times before 2020-01-01T00:00:00Z are clamped to
+// that epoch, as in NewFromParts, and times more than a minute in the future
+// are refused unless WithAllowFuture is also given.
+//
+// IDs with an explicit time do not use or advance the Generator's sequence:
+// their sequence is 0 and they are distinguished by the random field alone.
+func WithTime(t time.Time) Option {
+	return func(o *options) {
+		o.at = t
+		o.hasTime = true
+	}
+}
+
+// WithRandomSource makes New read the 8 bytes behind the 60-bit random field
+// from r instead of the Generator's entropy source; the top 4 bits are
+// discarded. If r fails or returns fewer than 8 bytes, NewE returns an error
+// wrapping ErrEntropy and New panics; the Generator's fallback is not used.
+//
+// A reader that is not a CSPRNG makes IDs predictable and weakens uniqueness,
+// since IDs in the same millisecond are then only kept apart by the sequence.
+func WithRandomSource(r io.Reader) Option {
+	return func(o *options) {
+		o.entropy = r
+	}
+}
+
+// WithAllowFuture lets New follow a Generator clock that jumps more than a
+// minute ahead of its last reading for the stream, beyond the real time that
+// has passed since, and accept a WithTime time more than a minute in the
+// future. Without it, such IDs are refused with ErrFutureTimestamp to catch
+// misconfigured clocks before they mint IDs that sort after everything
+// generated later. A jump is refused once; the next ID follows the clock, so
+// a lasting step such as an NTP correction or VM resume does not stop
+// generation. The guard needs the stream's sequence state, so the first ID
+// of a stream and IDs minted with WithMonotonicDisabled are not checked, and
+// the default Generator behind the package-level New, which would panic,
+// does not check clock jumps at all.
+func WithAllowFuture() Option {
+	return func(o *options) {
+		o.allowFuture = true
+	}
+}
+
+// WithStrict makes ParseWith and Decoder accept only canonical IDs: no
+// surrounding whitespace, no uppercase, and none of the aliases I, L, O, or U
+// in the payload or checksum.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithExpectedPrefix makes ParseWith and Decoder reject IDs whose prefix is
+// not p.
+func WithExpectedPrefix(p string) Option {
+	return func(o *options) {
+		o.expectedPrefix = p
+	}
+}
+
+// WithTimeBounds makes ParseWith and Decoder reject IDs whose embedded time
+// lies outside [notBefore, notAfter]. A zero time leaves that side unbounded.
+func WithTimeBounds(notBefore, notAfter time.Time) Option {
+	return func(o *options) {
+		o.notBefore, o.notAfter = 0, 0
+		if !notBefore.IsZero() {
+			o.notBefore = notBefore.UnixMilli()
+		}
+		if !notAfter.IsZero() {
+			o.notAfter = notAfter.UnixMilli()
+		}
+	}
+}
+
 var (
 	alpha          = []byte("0123456789abcdefghjkmnpqrstvwxyz") // crockford, lowercase
 	alphaRev       [256]byte
@@ -76,12 +385,37 @@ var (
 	ErrInvalidChecksum = errors.New("orderlyid: invalid checksum")
 	// ErrInvalidPayloadLength reports payloads that are not the required 32 characters.
 	ErrInvalidPayloadLength = errors.New("orderlyid: invalid payload length")
-	// ErrInvalidBase32 reports payloads that are not valid Crockford Base32.
+	// ErrInvalidBase32 reports payloads that are not valid Crockford Base32,
+	// or valid in the Alphabet of the Generator that parses them.
 	ErrInvalidBase32 = errors.New("orderlyid: invalid base32")
 	// ErrInvalidRandomHex reports invalid random hex input passed to NewFromPartsHex.
 	ErrInvalidRandomHex = errors.New("orderlyid: invalid random hex")
+	// ErrUnregisteredPrefix reports prefixes rejected by WithRequireRegistered.
+	ErrUnregisteredPrefix = errors.New("orderlyid: unregistered prefix")
+	// ErrConfusablePrefix reports prefixes rejected by WithConfusableCheck.
+	ErrConfusablePrefix = errors.New("orderlyid: confusable prefix")
+	// ErrEntropy reports a failure to read random bytes for a new ID.
+	ErrEntropy = errors.New("orderlyid: entropy source failed")
+	// ErrInvalidSignature reports signatures rejected by VerifySigned.
+	ErrInvalidSignature = errors.New("orderlyid: invalid signature")
+	// ErrTimeOutOfRange reports IDs rejected by WithTimeBounds.
+	ErrTimeOutOfRange = errors.New("orderlyid: time out of range")
+	// ErrFutureTimestamp reports clocks refused by the future-time guard; see
+	// WithAllowFuture.
+	ErrFutureTimestamp = errors.New("orderlyid: timestamp in the future")
+	// ErrInvalidOption reports option values or combinations that cannot
+	// produce or accept parseable IDs.
+	ErrInvalidOption = errors.New("orderlyid: invalid option")
+	// ErrUnsupportedVersion reports IDs whose wire version, in flag bits
+	// 7..6, is newer than the parser accepts.
+	ErrUnsupportedVersion = errors.New("orderlyid: unsupported version")
 )
 
+// MaxVersion is the highest wire version this package understands. Parse and
+// the other parsing functions reject IDs with a higher version; see
+// ParseWithMaxVersion.
+const MaxVersion uint8 = 0
+
 func init() {
 	for i := range alphaRev {
 		alphaRev[i] = 0xFF
@@ -105,78 +439,56 @@ func init() {
 	alphaValidMask['L'], alphaValidMask['l'] = true, true
 	alphaValidMask['O'], alphaValidMask['o'] = true, true
 	alphaValidMask['U'], alphaValidMask['u'] = true, true
+
+	crockford = &Alphabet{dec: alphaRev, fold: true}
+	copy(crockford.enc[:], alpha)
+	for _, b := range alpha {
+		crockford.canon[b] = true
+	}
 }
 
+// Flag bits stored in Parsed.Flags.
 const (
-	versionBits          = 0 // v1
-	privacyBitMask       = 1 << 5
-	epoch2020      int64 = 1577836800000 // 2020-01-01T00:00:00Z in ms
+	// FlagVersionMask covers bits 7..6, which carry the wire version
+	// (00 = v1).
+	FlagVersionMask uint8 = 0xC0
+	// FlagPrivacy marks IDs whose timestamp was rounded down to a privacy
+	// bucket.
+	FlagPrivacy uint8 = 1 << 5
+	// FlagRegion marks IDs whose top 4 shard bits carry a region identifier;
+	// see WithRegion.
+	FlagRegion uint8 = 1 << 4
+	// FlagApplicationMask covers bits 3..0, which the format leaves to
+	// applications; set them with WithFlags.
+	FlagApplicationMask uint8 = 0x0F
 )
 
-var (
-	mu     sync.Mutex
-	lastMs int64
-	seq12  uint16 // 12-bit
-)
+const epoch2020 int64 = 1577836800000 // 2020-01-01T00:00:00Z in ms
 
-// New generates a new OrderlyID such as "order_0r8h...".
+// New generates a new OrderlyID such as "order_0r8h..." using the default
+// Generator.
 //
 // The prefix must match the public ID type naming rules used by Parse. New
-// panics if the prefix is invalid or if cryptographic randomness cannot be
-// read.
+// panics if the prefix is invalid, if it is rejected by an option such as
+// WithRequireRegistered, or if cryptographic randomness cannot be read. Use
+// NewE to handle these conditions as errors.
 func New(prefix string, opts ...Option) string {
-	if !prefixRe.MatchString(prefix) {
-		panic("invalid prefix")
-	}
-	var o options
-	for _, fn := range opts {
-		fn(&o)
-	}
-
-	now := time.Now().UTC().UnixMilli()
-	if o.bucketSeconds > 0 {
-		bs := int64(o.bucketSeconds) * 1000
-		now = (now / bs) * bs
-	}
-	ms := now - epoch2020
-
-	mu.Lock()
-	if ms == lastMs {
-		seq12 = (seq12 + 1) & 0x0FFF
-	} else {
-		lastMs = ms
-		seq12 = 0
-	}
-	localSeq := seq12
-	mu.Unlock()
-
-	// flags
-	var flags byte = 0
-	if o.bucketSeconds > 0 {
-		flags |= privacyBitMask
-	}
-	// version in bits 7..6 already 0
-	// random 60 bits
-	rnd := make([]byte, 8)
-	if _, err := rand.Read(rnd); err != nil {
-		panic(err)
-	}
-
-	// mask top 4 bits to keep 60-bit space when viewed as uint64
-	rnd[0] &= 0x0F
-	random60 := binary.BigEndian.Uint64(rnd) // upper 4 bits are zero
-
-	body := pack(uint64(ms), flags, o.tenant, localSeq, o.shard, random60)
-	payload := b32encode(body[:])
+	return defaultGenerator.New(prefix, opts...)
+}
 
-	id := prefix + "_" + payload
+// AppendID generates a new ID as by New and appends it to dst, returning the
+// extended buffer. The output is byte-identical to New for the same clock,
+// entropy, and options. Without options, AppendID does not allocate in the
+// default monotonic mode once dst has room for the ID. It panics under the
+// same conditions as New.
+func AppendID(dst []byte, prefix string, opts ...Option) []byte {
+	return defaultGenerator.AppendID(dst, prefix, opts...)
+}
 
-	base := prefix + "_" + payload
-	if o.withChecksum {
-		cs := checksum4Base(base)
-		return base + "-" + cs
-	}
-	return id
+// NewE is like New but returns an error instead of panicking. See
+// Generator.NewE for the possible errors.
+func NewE(prefix string, opts ...Option) (string, error) {
+	return defaultGenerator.NewE(prefix, opts...)
 }
 
 // Parsed is the decoded representation of an OrderlyID.
@@ -195,57 +507,220 @@ type Parsed struct {
 	Shard uint16
 	// Random is the 60-bit random suffix stored in the payload.
 	Random uint64
+	// HasChecksum reports whether the parsed string carried a checksum
+	// suffix.
+	HasChecksum bool
+	// ChecksumLen is the length of the checksum suffix: 4, 6, or 8, or 0 if
+	// there is none.
+	ChecksumLen int
+	// Region is the region identifier stamped by WithRegion, taken from the
+	// top 4 shard bits. It is 0 unless Flags has FlagRegion set.
+	Region uint8
+
+	// epochShift is the epoch of the Generator that parsed the ID, set
+	// with WithEpoch, minus 2020-01-01 UTC in ms; zero for the default.
+	epochShift int64
 }
 
 // Parse decodes an OrderlyID string and returns its components.
 //
+// Parse is lenient, as suits user input: it ignores surrounding whitespace
+// and accepts uppercase and the aliases I, L, O, and U, so several strings can
+// decode to the same ID. Use ParseStrict where only the canonical form may be
+// accepted, such as before a uniqueness check.
+//
 // Parse may return errors wrapping ErrInvalidFormat, ErrInvalidPrefix,
-// ErrInvalidChecksum, ErrInvalidPayloadLength, or ErrInvalidBase32.
+// ErrInvalidChecksum, ErrInvalidPayloadLength, ErrInvalidBase32, or
+// ErrUnsupportedVersion for IDs with a version above MaxVersion.
 func Parse(s string) (*Parsed, error) {
-	s = strings.TrimSpace(s)
-	base := s
-	if i := strings.LastIndexByte(s, '-'); i >= 0 {
-		base = s[:i]
-		csGiven := s[i+1:]
-		if len(csGiven) != 4 {
-			return nil, fmt.Errorf("%w: must be 4 chars", ErrInvalidChecksum)
+	p := new(Parsed)
+	if err := parseInto(s, p, false); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MustParse is like Parse but panics if s cannot be parsed. It simplifies
+// initialization of package-level variables and test fixtures holding known
+// IDs; it must not be used on untrusted input. The panic value is an error
+// wrapping the Parse error.
+func MustParse(s string) *Parsed {
+	p, err := Parse(s)
+	if err != nil {
+		panic(fmt.Errorf("orderlyid: MustParse(%q): %w", s, err))
+	}
+	return p
+}
+
+// ParseStrict is like Parse but accepts only the canonical form produced by
+// New without WithUppercaseOutput or WithGroupingDashes: no surrounding
+// whitespace, and payload and checksum written in the lowercase alphabet
+// without aliases. A checksum suffix is still verified. It is equivalent to
+// ParseWith(s, WithStrict()).
+//
+// ParseStrict returns an error wrapping ErrInvalidBase32 or
+// ErrInvalidChecksum for non-canonical characters in the payload or checksum,
+// ErrInvalidFormat for surrounding whitespace, and otherwise any error
+// returned by Parse.
+func ParseStrict(s string) (*Parsed, error) {
+	p := new(Parsed)
+	if err := parseInto(s, p, true); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// IsValid reports whether s parses, including its checksum if present. It
+// does not allocate.
+func IsValid(s string) bool {
+	var p Parsed
+	return parseInto(s, &p, false) == nil
+}
+
+// ParseWithMaxVersion is like Parse but accepts wire versions up to max
+// instead of MaxVersion. Fields of versions above MaxVersion are decoded with
+// the current layout, which a future version may not share, so callers
+// should route on Parsed.Version before relying on them. It returns an error
+// wrapping ErrUnsupportedVersion if the version exceeds max.
+func ParseWithMaxVersion(s string, max uint8) (*Parsed, error) {
+	p := new(Parsed)
+	if err := crockford.parseInto(s, p, false, max); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseInto is like Parse but decodes into dst instead of allocating a new
+// Parsed. dst is only modified if ParseInto succeeds.
+func ParseInto(s string, dst *Parsed) error {
+	return parseInto(s, dst, false)
+}
+
+// parseInto implements Parse. In strict mode, surrounding whitespace,
+// uppercase, and the aliases I, L, O, and U are rejected.
+func parseInto(s string, dst *Parsed, strict bool) error {
+	return crockford.parseInto(s, dst, strict, MaxVersion)
+}
+
+// parseInto decodes s with payload and checksum symbols from a. In strict
+// mode, surrounding whitespace and symbols outside a's canonical set are
+// rejected. IDs with a wire version above maxVersion are rejected.
+func (a *Alphabet) parseInto(s string, dst *Parsed, strict bool, maxVersion uint8) error {
+	if t := strings.TrimSpace(s); t != s {
+		if strict {
+			return fmt.Errorf("%w: surrounding whitespace", ErrInvalidFormat)
 		}
-		expected := checksum4Base(base)
-		if !strings.EqualFold(csGiven, expected) {
-			return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidChecksum)
+		s = t
+	}
+	base, csGiven, hasChecksum := s, "", false
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		base, csGiven, hasChecksum = s[:i], s[i+1:], true
+		if !validChecksumLen(len(csGiven)) {
+			return fmt.Errorf("%w: must be 4, 6, or 8 chars", ErrInvalidChecksum)
 		}
 	}
 	i := strings.IndexByte(base, '_')
 	if i <= 0 {
-		return nil, fmt.Errorf("%w: missing prefix separator", ErrInvalidFormat)
+		return fmt.Errorf("%w: missing prefix separator", ErrInvalidFormat)
 	}
 	prefix := base[:i]
 	if !prefixRe.MatchString(prefix) {
-		return nil, fmt.Errorf("%w: must match [a-z][a-z0-9]{1,30}", ErrInvalidPrefix)
+		return fmt.Errorf("%w: must match [a-z][a-z0-9]{1,30}", ErrInvalidPrefix)
 	}
 	payload := base[i+1:]
 	if len(payload) != 32 {
-		return nil, fmt.Errorf("%w: must be 32 chars", ErrInvalidPayloadLength)
+		return fmt.Errorf("%w: must be 32 chars", ErrInvalidPayloadLength)
 	}
 	for j := 0; j < 32; j++ {
-		if alphaRev[payload[j]] == 0xFF {
-			return nil, fmt.Errorf("%w: invalid character at pos %d", ErrInvalidBase32, j)
+		if a.dec[payload[j]] == 0xFF {
+			return fmt.Errorf("%w: invalid character at pos %d", ErrInvalidBase32, j)
+		}
+		if strict && !a.canon[payload[j]] {
+			return fmt.Errorf("%w: non-canonical character at pos %d", ErrInvalidBase32, j)
 		}
 	}
-	buf, err := b32decode(payload)
-	if err != nil {
+	if strict {
+		for j := 0; j < len(csGiven); j++ {
+			if !a.canon[csGiven[j]] {
+				return fmt.Errorf("%w: non-canonical character", ErrInvalidChecksum)
+			}
+		}
+	}
+	// The checksum is only computed once the base is known to be well formed;
+	// checksumN panics on malformed input.
+	if hasChecksum {
+		sum := a.checksumN(prefix, payload, len(csGiven))
+		if !a.checksumEqual(csGiven, sum[:len(csGiven)]) {
+			return fmt.Errorf("%w: checksum mismatch", ErrInvalidChecksum)
+		}
+	}
+	var buf [20]byte
+	if err := a.decodeInto(&buf, payload); err != nil {
+		return err
+	}
+	ms, flags, tenant, seq, shard, random60 := unpack(buf[:])
+	if v := (flags & FlagVersionMask) >> 6; v > maxVersion {
+		return fmt.Errorf("%w: version %d, want at most %d", ErrUnsupportedVersion, v, maxVersion)
+	}
+	*dst = Parsed{
+		Prefix:      prefix,
+		TimeMs:      int64(ms) + epoch2020,
+		Flags:       flags,
+		Tenant:      tenant,
+		Seq:         seq,
+		Shard:       shard,
+		Random:      random60,
+		HasChecksum: hasChecksum,
+		ChecksumLen: len(csGiven),
+		Region:      regionOf(flags, shard),
+	}
+	return nil
+}
+
+// regionOf returns the region stored in shard if flags has FlagRegion set.
+func regionOf(flags byte, shard uint16) uint8 {
+	if flags&FlagRegion == 0 {
+		return 0
+	}
+	return uint8(shard >> 12)
+}
+
+// ParseWith is like Parse but additionally applies the validation options in
+// opts, such as WithRequireRegistered, WithStrict, WithExpectedPrefix, and
+// WithTimeBounds.
+//
+// ParseWith may return any error returned by Parse, or an error wrapping
+// ErrUnregisteredPrefix, ErrTimeOutOfRange, or ErrInvalidOption.
+func ParseWith(s string, opts ...Option) (*Parsed, error) {
+	o := buildOptions(opts)
+	if err := o.validate(); err != nil {
 		return nil, err
 	}
-	ms, flags, tenant, seq, shard, random60 := unpack(buf)
-	return &Parsed{
-		Prefix: prefix,
-		TimeMs: int64(ms) + epoch2020,
-		Flags:  flags,
-		Tenant: tenant,
-		Seq:    seq,
-		Shard:  shard,
-		Random: random60,
-	}, nil
+	p := new(Parsed)
+	if err := o.parse(s, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parse decodes s into dst and applies the validation options in o. dst is
+// only modified on success.
+func (o *options) parse(s string, dst *Parsed) error {
+	var p Parsed
+	if err := parseInto(s, &p, o.strict); err != nil {
+		return err
+	}
+	if err := o.checkPrefixRules(p.Prefix); err != nil {
+		return err
+	}
+	if o.expectedPrefix != "" && p.Prefix != o.expectedPrefix {
+		return fmt.Errorf("%w: got %q, want %q", ErrInvalidPrefix, p.Prefix, o.expectedPrefix)
+	}
+	if o.notBefore != 0 && p.TimeMs < o.notBefore || o.notAfter != 0 && p.TimeMs > o.notAfter {
+		return fmt.Errorf("%w: %d", ErrTimeOutOfRange, p.TimeMs)
+	}
+	*dst = p
+	return nil
 }
 
 // Packing layout (big-endian)
@@ -300,6 +775,17 @@ func b32encode(src []byte) string {
 		panic("b32encode expects 20 bytes")
 	}
 	var out [32]byte
+	b32put(&out, (*[20]byte)(src))
+	return string(out[:])
+}
+
+// b32put encodes the 160-bit src into out without allocating.
+func b32put(out *[32]byte, src *[20]byte) {
+	crockford.put(out, src)
+}
+
+// put encodes the 160-bit src into out with a's symbols.
+func (a *Alphabet) put(out *[32]byte, src *[20]byte) {
 	var acc uint32
 	var bits uint
 	var j int
@@ -309,30 +795,26 @@ func b32encode(src []byte) string {
 		for bits >= 5 {
 			bits -= 5
 			idx := byte((acc >> bits) & 31)
-			out[j] = alpha[idx]
+			out[j] = a.enc[idx]
 			j++
 		}
 	}
-	if bits != 0 { // for 160 bits, this should be zero
-		idx := byte((acc << (5 - bits)) & 31)
-		out[j] = alpha[idx]
-		j++
-	}
-	return string(out[:])
+	// 160 bits split evenly into 32 symbols, so no bits remain.
 }
 
-func b32decode(s string) ([]byte, error) {
+// decodeInto decodes the 32-symbol payload s, written with a's symbols, into
+// out.
+func (a *Alphabet) decodeInto(out *[20]byte, s string) error {
 	if len(s) != 32 {
-		return nil, fmt.Errorf("%w: must be 32 chars", ErrInvalidPayloadLength)
+		return fmt.Errorf("%w: must be 32 chars", ErrInvalidPayloadLength)
 	}
-	out := make([]byte, 20)
 	var acc uint32
 	var bits uint
 	var j int
 	for i := 0; i < len(s); i++ {
-		v := alphaRev[s[i]]
+		v := a.dec[s[i]]
 		if v == 0xFF {
-			return nil, fmt.Errorf("%w: invalid character at pos %d", ErrInvalidBase32, i)
+			return fmt.Errorf("%w: invalid character at pos %d", ErrInvalidBase32, i)
 		}
 		acc = (acc << 5) | uint32(v)
 		bits += 5
@@ -343,78 +825,190 @@ func b32decode(s string) ([]byte, error) {
 		}
 	}
 	if j != 20 || bits != 0 {
-		return nil, fmt.Errorf("%w: invalid payload", ErrInvalidBase32)
+		return fmt.Errorf("%w: invalid payload", ErrInvalidBase32)
+	}
+	return nil
+}
+
+// Checksum (Bech32-style polymod, 4 chars = 20 bits by default; 6 and 8
+// chars for WithChecksumLen)
+
+// maxChecksumLen is the length of the longest supported checksum suffix.
+const maxChecksumLen = 8
+
+// validChecksumLen reports whether n is a supported checksum length.
+func validChecksumLen(n int) bool {
+	return n == 4 || n == 6 || n == 8
+}
+
+// checksumEqual reports whether the given checksum matches want, in time
+// independent of their contents. ASCII case is ignored if a folds case.
+func (a *Alphabet) checksumEqual(given string, want []byte) bool {
+	if len(given) != len(want) || len(given) > maxChecksumLen {
+		return false
+	}
+	var b [maxChecksumLen]byte
+	for i := 0; i < len(given); i++ {
+		b[i] = given[i]
+		if a.fold {
+			b[i] = lower(b[i])
+		}
 	}
-	return out, nil
+	return subtle.ConstantTimeCompare(b[:len(given)], want) == 1
 }
 
-// Checksum (Bech32-style polymod, 4 chars = 20 bits)
 func checksum4Base(base string) string {
-	// base is "prefix_payload"; use hrp = "prefix_" (lowercased), data = payload indices
+	// base is "prefix_payload"
 	idx := strings.IndexByte(base, '_')
 	if idx <= 0 {
 		panic("checksum4Base: bad base")
 	}
-	hrp := strings.ToLower(base[:idx+1]) // include '_'
-	payload := strings.ToLower(base[idx+1:])
-	values := hrpExpand(hrp)
-	values = append(values, payloadTo5bits(payload)...)
-	// append 4 zero groups for 20-bit checksum
-	values = append(values, 0, 0, 0, 0)
-	pm := bech32Polymod(values) ^ 1
-	// top 20 bits as 4 Base32 chars
+	cs := checksum4(strings.ToLower(base[:idx]), base[idx+1:])
+	return string(cs[:])
+}
+
+// checksum4 computes the 4-symbol checksum of prefix and payload with the
+// exported primitives, as
+//
+//	Polymod(HRPExpand(prefix+"_") + payload values + [0 0 0 0]) ^ 1
+//
+// keeping the low 20 bits. It backs Checksum and checksum4Base; New and
+// Parse use checksumN, which streams the same values through polymodStep
+// instead of building them, so that they do not allocate.
+func checksum4(prefix, payload string) [4]byte {
+	values := HRPExpand(prefix + "_")
+	for i := 0; i < len(payload); i++ {
+		v := crockford.dec[payload[i]]
+		if v == 0xFF {
+			panic("invalid payload for checksum")
+		}
+		values = append(values, v)
+	}
+	pm := Polymod(append(values, 0, 0, 0, 0)) ^ 1
 	var out [4]byte
-	for i := 0; i < 4; i++ {
-		idx := byte((pm >> uint(5*(3-i))) & 31)
-		out[i] = alpha[idx]
+	for i := range out {
+		out[i] = crockford.enc[(pm>>uint(5*(3-i)))&31]
 	}
-	return string(out[:])
+	return out
 }
 
-func payloadTo5bits(payload string) []byte {
-	out := make([]byte, len(payload))
+// checksumN computes the n-symbol checksum of prefix and payload with a's
+// symbols; n must be 4, 6, or 8, and only the first n bytes of the result are
+// used. The 4- and 6-symbol checksums run the Bech32 polymod with n zero
+// groups appended and keep the low 5n bits; 6 symbols is the full Bech32
+// checksum. The 30-bit Bech32 state cannot fill 8 symbols, so the 8-symbol
+// checksum runs the 40-bit CashAddr polymod instead. All lengths XOR the
+// result with 1.
+func (a *Alphabet) checksumN(prefix, payload string, n int) [maxChecksumLen]byte {
+	step := bech32Step
+	if n == 8 {
+		step = cashaddrStep
+	}
+	chk := uint64(1)
+	// hrp expansion: high bits of each hrp byte, a zero, then the low bits
+	for i := 0; i < len(prefix); i++ {
+		chk = step(chk, prefix[i]>>5)
+	}
+	chk = step(chk, '_'>>5)
+	chk = step(chk, 0)
+	for i := 0; i < len(prefix); i++ {
+		chk = step(chk, prefix[i]&31)
+	}
+	chk = step(chk, '_'&31)
 	for i := 0; i < len(payload); i++ {
-		v := alphaRev[payload[i]]
+		v := a.dec[payload[i]]
 		if v == 0xFF {
 			panic("invalid payload for checksum")
 		}
-		out[i] = v
+		chk = step(chk, v)
+	}
+	// n zero groups for the 5n-bit checksum
+	for i := 0; i < n; i++ {
+		chk = step(chk, 0)
+	}
+	pm := chk ^ 1
+	var out [maxChecksumLen]byte
+	for i := 0; i < n; i++ {
+		out[i] = a.enc[(pm>>uint(5*(n-1-i)))&31]
 	}
 	return out
 }
 
-func hrpExpand(s string) []byte {
+// Polymod returns the Bech32 checksum polymod of values, each of which must be
+// a 5-bit value. OrderlyID checksums are Polymod over HRPExpand(prefix+"_"),
+// the payload symbol values (the index of each symbol in the Crockford
+// alphabet), and four zeros, XORed with 1; the four checksum symbols encode
+// the low 20 bits of the result, most significant first.
+func Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		chk = polymodStep(chk, v)
+	}
+	return chk
+}
+
+// HRPExpand expands the human-readable part s for Polymod: the high 3 bits of
+// each byte, a zero separator, then the low 5 bits of each byte. For
+// OrderlyIDs the human-readable part is the lowercase prefix followed by "_".
+func HRPExpand(s string) []byte {
 	vals := make([]byte, 0, len(s)*2+1)
 	for i := 0; i < len(s); i++ {
-		vals = append(vals, byte(s[i])>>5)
+		vals = append(vals, s[i]>>5)
 	}
 	vals = append(vals, 0)
 	for i := 0; i < len(s); i++ {
-		vals = append(vals, byte(s[i])&31)
+		vals = append(vals, s[i]&31)
 	}
 	return vals
 }
 
-func bech32Polymod(values []byte) uint32 {
-	chk := uint32(1)
-	for _, v := range values {
-		b := chk >> 25
-		chk = ((chk & 0x1ffffff) << 5) ^ uint32(v)
-		if (b & 0x01) != 0 {
-			chk ^= 0x3b6a57b2
-		}
-		if (b & 0x02) != 0 {
-			chk ^= 0x26508e6d
-		}
-		if (b & 0x04) != 0 {
-			chk ^= 0x1ea119fa
-		}
-		if (b & 0x08) != 0 {
-			chk ^= 0x3d4233dd
-		}
-		if (b & 0x10) != 0 {
-			chk ^= 0x2a1462b3
-		}
+// bech32Step is polymodStep on the 64-bit state used by checksumN.
+func bech32Step(chk uint64, v byte) uint64 {
+	return uint64(polymodStep(uint32(chk), v))
+}
+
+// cashaddrStep feeds one 5-bit value into the 40-bit CashAddr polymod state
+// chk, which backs 8-symbol checksums.
+func cashaddrStep(chk uint64, v byte) uint64 {
+	b := chk >> 35
+	chk = ((chk & 0x07ffffffff) << 5) ^ uint64(v)
+	if (b & 0x01) != 0 {
+		chk ^= 0x98f2bc8e61
+	}
+	if (b & 0x02) != 0 {
+		chk ^= 0x79b76d99e2
+	}
+	if (b & 0x04) != 0 {
+		chk ^= 0xf33e5fb3c4
+	}
+	if (b & 0x08) != 0 {
+		chk ^= 0xae2eabe2a8
+	}
+	if (b & 0x10) != 0 {
+		chk ^= 0x1e4f43e470
+	}
+	return chk
+}
+
+// polymodStep feeds one 5-bit value into the polymod state chk. It is shared
+// by Polymod and the allocation-free checksumN.
+func polymodStep(chk uint32, v byte) uint32 {
+	b := chk >> 25
+	chk = ((chk & 0x1ffffff) << 5) ^ uint32(v)
+	if (b & 0x01) != 0 {
+		chk ^= 0x3b6a57b2
+	}
+	if (b & 0x02) != 0 {
+		chk ^= 0x26508e6d
+	}
+	if (b & 0x04) != 0 {
+		chk ^= 0x1ea119fa
+	}
+	if (b & 0x08) != 0 {
+		chk ^= 0x3d4233dd
+	}
+	if (b & 0x10) != 0 {
+		chk ^= 0x2a1462b3
 	}
 	return chk
 }
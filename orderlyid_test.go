@@ -1,8 +1,13 @@
 package orderlyid
 
 import (
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -45,6 +50,33 @@ func TestRejectBadPrefix(t *testing.T) {
 	_ = New("Bad!")
 }
 
+func TestNewEReturnsErrorsInsteadOfPanicking(t *testing.T) {
+	if _, err := NewE("Bad!"); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+	id, err := NewE("order", WithTenant(4))
+	if err != nil {
+		t.Fatalf("NewE: %v", err)
+	}
+	if p := mustParse(t, id); p.Tenant != 4 {
+		t.Fatalf("tenant = %d, want 4", p.Tenant)
+	}
+
+	SetDefaultEntropy(failingReader{})
+	defer SetDefaultEntropy(nil)
+	if _, err := NewE("order"); !errors.Is(err, ErrEntropy) {
+		t.Fatalf("expected ErrEntropy, got %v", err)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("New did not panic on entropy failure")
+		} else if err, ok := r.(error); !ok || !errors.Is(err, ErrEntropy) {
+			t.Fatalf("New panicked with %v, want ErrEntropy", r)
+		}
+	}()
+	New("order")
+}
+
 func TestChecksumRoundTrip(t *testing.T) {
 	id := New("order", WithChecksum(true))
 	if !strings.Contains(id, "-") || len(id) != len("order_")+32+1+4 {
@@ -53,8 +85,12 @@ func TestChecksumRoundTrip(t *testing.T) {
 	if _, err := Parse(id); err != nil {
 		t.Fatalf("parse with checksum failed: %v", err)
 	}
-	// Tamper last char
-	bad := id[:len(id)-1] + "0"
+	// Tamper last char, making sure it actually changes
+	repl := "0"
+	if strings.HasSuffix(id, repl) {
+		repl = "1"
+	}
+	bad := id[:len(id)-1] + repl
 	if _, err := Parse(bad); err == nil {
 		t.Fatalf("expected checksum mismatch")
 	} else if !errors.Is(err, ErrInvalidChecksum) {
@@ -62,6 +98,168 @@ func TestChecksumRoundTrip(t *testing.T) {
 	}
 }
 
+func TestUppercaseOutputRoundTrip(t *testing.T) {
+	for _, checksum := range []bool{false, true} {
+		id := New("order", WithTenant(7), WithShard(9), WithChecksum(checksum), WithUppercaseOutput())
+		rest, ok := strings.CutPrefix(id, "order_")
+		if !ok {
+			t.Fatalf("prefix not kept lowercase: %s", id)
+		}
+		if rest != strings.ToUpper(rest) {
+			t.Fatalf("payload not uppercase: %s", id)
+		}
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse %s: %v", id, err)
+		}
+		if p.Prefix != "order" || p.Tenant != 7 || p.Shard != 9 || p.HasChecksum != checksum {
+			t.Fatalf("unexpected parse result: %+v", p)
+		}
+		lower, err := Parse("order_" + strings.ToLower(rest))
+		if err != nil {
+			t.Fatalf("parse lowercase form: %v", err)
+		}
+		if *lower != *p {
+			t.Fatalf("uppercase and lowercase forms differ:\n%+v\n%+v", p, lower)
+		}
+	}
+}
+
+func TestChecksumMixedCase(t *testing.T) {
+	id, err := NewFromParts(Components{Prefix: "order", TimeMs: 1735689600000, Random60: 0xABCDEF}, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	i := strings.LastIndexByte(id, '-')
+	cs := []byte(id[i+1:])
+	for j := range cs {
+		if j%2 == 0 {
+			cs[j] = byte(strings.ToUpper(string(cs[j]))[0])
+		}
+	}
+	variants := []string{
+		id[:i+1] + strings.ToUpper(id[i+1:]),
+		id[:i+1] + string(cs),
+		"order_" + strings.ToUpper(id[len("order_"):]),
+	}
+	for _, v := range variants {
+		if _, err := Parse(v); err != nil {
+			t.Fatalf("Parse(%s): %v", v, err)
+		}
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	id := New("order", WithTenant(5))
+	if p := MustParse(id); p.Prefix != "order" || p.Tenant != 5 {
+		t.Fatalf("unexpected parse result: %+v", p)
+	}
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || !errors.Is(err, ErrInvalidPayloadLength) || !strings.Contains(err.Error(), `"order_123"`) {
+			t.Fatalf("MustParse panicked with %v, want ErrInvalidPayloadLength naming the input", r)
+		}
+	}()
+	MustParse("order_123")
+}
+
+func TestIsValid(t *testing.T) {
+	id := New("order", WithChecksum(true))
+	base, cs, _ := strings.Cut(id, "-")
+	badCS := "0000"
+	if cs == badCS {
+		badCS = "1111"
+	}
+	tests := []struct {
+		name string
+		in   string
+		want bool
+	}{
+		{name: "with checksum", in: id, want: true},
+		{name: "without checksum", in: base, want: true},
+		{name: "uppercase payload", in: "order_" + strings.ToUpper(base[len("order_"):]), want: true},
+		{name: "whitespace", in: " " + id + "\n", want: true},
+		{name: "empty", in: "", want: false},
+		{name: "no separator", in: "order", want: false},
+		{name: "bad prefix", in: "Order_" + base[len("order_"):], want: false},
+		{name: "short payload", in: "order_123", want: false},
+		{name: "bad character", in: base[:len(base)-1] + "!", want: false},
+		{name: "bad checksum", in: base + "-" + badCS, want: false},
+		{name: "short checksum", in: base + "-" + cs[:3], want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValid(tt.in); got != tt.want {
+				t.Fatalf("IsValid(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+	if allocs := testing.AllocsPerRun(100, func() { IsValid(id) }); allocs != 0 {
+		t.Fatalf("IsValid allocated %v times per run, want 0", allocs)
+	}
+}
+
+func BenchmarkIsValid(b *testing.B) {
+	id := New("order", WithChecksum(true))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !IsValid(id) {
+			b.Fatal("IsValid = false")
+		}
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	// The checksum needs a letter for the uppercase case to differ.
+	id := New("order", WithChecksum(true))
+	for cs := id[len(id)-4:]; strings.ToUpper(cs) == cs; cs = id[len(id)-4:] {
+		id = New("order", WithChecksum(true))
+	}
+	base, cs, _ := strings.Cut(id, "-")
+	payload := base[len("order_"):]
+	letter := strings.IndexAny(payload, "abcdefghjkmnpqrstvwxyz")
+	mixed := payload[:letter] + strings.ToUpper(payload[letter:letter+1]) + payload[letter+1:]
+	badCS := "0000"
+	if cs == badCS {
+		badCS = "1111"
+	}
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{name: "canonical", in: id},
+		{name: "canonical without checksum", in: base},
+		{name: "uppercase payload", in: "order_" + strings.ToUpper(payload) + "-" + cs, want: ErrInvalidBase32},
+		{name: "mixed case payload", in: "order_" + mixed, want: ErrInvalidBase32},
+		{name: "uppercase checksum", in: base + "-" + strings.ToUpper(cs), want: ErrInvalidChecksum},
+		{name: "alias", in: "order_" + strings.Replace(payload, "0", "o", 1), want: ErrInvalidBase32},
+		{name: "whitespace", in: id + "\n", want: ErrInvalidFormat},
+		{name: "bad checksum", in: base + "-" + badCS, want: ErrInvalidChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseStrict(tt.in)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+				if _, err := Parse(tt.in); err != nil && tt.want != ErrInvalidChecksum {
+					t.Fatalf("Parse rejected lenient input: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStrict: %v", err)
+			}
+			if want := mustParse(t, tt.in); *p != *want {
+				t.Fatalf("ParseStrict = %+v, want %+v", p, want)
+			}
+		})
+	}
+}
+
 func TestParseErrorsSupportErrorsIs(t *testing.T) {
 	tests := []struct {
 		name string
@@ -101,3 +299,280 @@ func TestNewFromPartsErrorsSupportErrorsIs(t *testing.T) {
 		t.Fatalf("expected ErrInvalidRandomHex, got %v", err)
 	}
 }
+
+func TestPrefixLengthLimits(t *testing.T) {
+	id := New("shipment")
+	if _, err := ParseWith(id); err != nil {
+		t.Fatalf("default limits rejected %s: %v", id, err)
+	}
+	if _, err := ParseWith(id, WithMaxPrefixLength(5)); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix under max length 5, got %v", err)
+	}
+	if _, err := ParseWith(id, WithMaxPrefixLength(8)); err != nil {
+		t.Fatalf("max length 8 rejected %s: %v", id, err)
+	}
+	if _, err := ParseWith(New("ab"), WithMinPrefixLength(3)); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix under min length 3, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on over-long prefix")
+		}
+	}()
+	_ = New("shipment", WithMaxPrefixLength(5))
+}
+
+func TestFromBytesRoundTrip(t *testing.T) {
+	for _, withChecksum := range []bool{false, true} {
+		id := New("order", WithTenant(9), WithShard(4242), WithChecksum(withChecksum))
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		got, err := FromBytes(p.Prefix, p.Bytes(), withChecksum)
+		if err != nil {
+			t.Fatalf("FromBytes: %v", err)
+		}
+		if got != id {
+			t.Fatalf("FromBytes = %s, want %s", got, id)
+		}
+		back, err := Parse(got)
+		if err != nil {
+			t.Fatalf("parse FromBytes output: %v", err)
+		}
+		if *back != *p {
+			t.Fatalf("fields mismatch: got %+v want %+v", back, p)
+		}
+	}
+
+	if _, err := FromBytes("Bad!", [20]byte{}, false); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+}
+
+// TestConcurrentNewUnique hammers the shared generator state from many
+// goroutines. Run with -race to check the locking around the sequence counter.
+func TestConcurrentNewUnique(t *testing.T) {
+	const workers, perWorker = 32, 500
+	ids := make(chan string, workers*perWorker)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for i := 0; i < perWorker; i++ {
+				ids <- New("xx")
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]struct{}, workers*perWorker)
+	perMs := map[int64]map[uint16]int{}
+	for id := range ids {
+		if _, dup := seen[id]; dup {
+			t.Fatalf("duplicate id %s", id)
+		}
+		seen[id] = struct{}{}
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse %s: %v", id, err)
+		}
+		if perMs[p.TimeMs] == nil {
+			perMs[p.TimeMs] = map[uint16]int{}
+		}
+		perMs[p.TimeMs][p.Seq]++
+	}
+	if len(seen) != workers*perWorker {
+		t.Fatalf("got %d ids, want %d", len(seen), workers*perWorker)
+	}
+	// The sequence counter is shared across goroutines, so sequence numbers
+	// within one millisecond never repeat.
+	for ms, seqs := range perMs {
+		n := 0
+		for _, c := range seqs {
+			n += c
+		}
+		if len(seqs) != n {
+			t.Fatalf("ms %d: %d ids share %d sequence numbers", ms, n, len(seqs))
+		}
+	}
+}
+
+// TestNoCollisionsUnderLoad generates IDs as fast as possible from one
+// goroutine and checks that none repeat. It reports the highest sequence value
+// observed, which shows how close a burst came to wrapping the 12-bit counter.
+func TestNoCollisionsUnderLoad(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping collision load test in -short mode")
+	}
+	const n = 1_000_000
+	seen := make(map[string]struct{}, n)
+	var maxSeq uint16
+	var maxSeqMs int64
+	for i := 0; i < n; i++ {
+		id := New("load")
+		if _, dup := seen[id]; dup {
+			t.Fatalf("collision after %d ids: %s", i, id)
+		}
+		seen[id] = struct{}{}
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse %s: %v", id, err)
+		}
+		if p.Seq > maxSeq {
+			maxSeq, maxSeqMs = p.Seq, p.TimeMs
+		}
+	}
+	t.Logf("%d ids, no collisions; max seq %d (of 4095) at %s", n, maxSeq,
+		time.UnixMilli(maxSeqMs).UTC().Format(time.RFC3339Nano))
+}
+
+func TestWithRegion(t *testing.T) {
+	g := NewGenerator(
+		WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))),
+		WithEntropy(repeatReader{0}),
+	)
+	a := mustParse(t, g.New("order", WithRegion(3), WithShard(0xFABC)))
+	if a.Region != 3 || !HasFlag(a, FlagRegion) {
+		t.Fatalf("region not stamped: %+v", a)
+	}
+	if a.Shard != 0x3ABC {
+		t.Fatalf("shard = %#x, want region in the top 4 bits and 0xABC below", a.Shard)
+	}
+
+	// Same millisecond, sequence, shard, and entropy: only the region differs.
+	g2 := NewGenerator(
+		WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))),
+		WithEntropy(repeatReader{0}),
+	)
+	b := mustParse(t, g2.New("order", WithRegion(12), WithShard(0xFABC)))
+	if b.Region != 12 || a.Seq != b.Seq {
+		t.Fatalf("unexpected second id: %+v", b)
+	}
+	sa, sb := a.MustString(), b.MustString()
+	if sa == sb {
+		t.Fatalf("regions 3 and 12 produced the same id %s", sa)
+	}
+	if back := mustParse(t, sb); back.Region != 12 {
+		t.Fatalf("region lost in round trip: %+v", back)
+	}
+
+	plain := mustParse(t, g.New("order", WithShard(0xFABC)))
+	if plain.Region != 0 || HasFlag(plain, FlagRegion) || plain.Shard != 0xFABC {
+		t.Fatalf("region reported without WithRegion: %+v", plain)
+	}
+}
+
+func TestWithShardFromBytesUsingDistribution(t *testing.T) {
+	fnv32a := func(b []byte) uint32 {
+		h := fnv.New32a()
+		h.Write(b)
+		return h.Sum32()
+	}
+	hashes := []struct {
+		name string
+		h    func([]byte) uint32
+	}{
+		{name: "crc32", h: crc32.ChecksumIEEE},
+		{name: "fnv32a", h: fnv32a},
+	}
+	// Four sequential keys per shard: a uniform hash leaves about e^-4 (1.8%)
+	// of the shards empty, and no shard should come close to 32 keys.
+	const keys = 4 << 16
+	for _, tt := range hashes {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make([]int, 1<<16)
+			key := make([]byte, 8)
+			for i := 0; i < keys; i++ {
+				binary.BigEndian.PutUint64(key, uint64(i))
+				o := buildOptions([]Option{WithShardFromBytesUsing(key, tt.h)})
+				counts[o.shard]++
+			}
+			empty, most := 0, 0
+			for _, c := range counts {
+				if c == 0 {
+					empty++
+				}
+				most = max(most, c)
+			}
+			if empty > len(counts)/20 {
+				t.Fatalf("%d of %d shards empty", empty, len(counts))
+			}
+			if most >= 32 {
+				t.Fatalf("busiest shard holds %d keys", most)
+			}
+		})
+	}
+
+	key := []byte("customer-42")
+	def := buildOptions([]Option{WithShardFromBytes(key)})
+	if o := buildOptions([]Option{WithShardFromBytesUsing(key, nil)}); o.shard != def.shard {
+		t.Fatalf("nil hash shard = %d, want default %d", o.shard, def.shard)
+	}
+}
+
+func TestWithShardModulo(t *testing.T) {
+	for _, ring := range []uint16{1, 7, 64, 1000, 65535} {
+		for i := 0; i < 2000; i++ {
+			key := []byte(fmt.Sprintf("customer-%d", i))
+			a := buildOptions([]Option{WithShardModulo(key, ring)})
+			b := buildOptions([]Option{WithShardModulo(key, ring)})
+			if a.shard != b.shard {
+				t.Fatalf("ring %d key %q: shard %d then %d", ring, key, a.shard, b.shard)
+			}
+			if a.shard >= ring {
+				t.Fatalf("ring %d key %q: shard %d out of range", ring, key, a.shard)
+			}
+		}
+	}
+
+	p := mustParse(t, New("order", WithShardModulo([]byte("customer-1"), 64)))
+	want := buildOptions([]Option{WithShardModulo([]byte("customer-1"), 64)}).shard
+	if p.Shard != want {
+		t.Fatalf("parsed shard = %d, want %d", p.Shard, want)
+	}
+
+	if _, err := NewE("order", WithShardModulo([]byte("customer-1"), 0)); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for ring 0, got %v", err)
+	}
+}
+
+func TestConflictingOptionsRejected(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "min above max prefix length", opts: []Option{WithMinPrefixLength(6), WithMaxPrefixLength(5)}},
+		{name: "max prefix length below 2", opts: []Option{WithMaxPrefixLength(1)}},
+		{name: "negative max prefix length", opts: []Option{WithMaxPrefixLength(-1)}},
+		{name: "min prefix length above 31", opts: []Option{WithMinPrefixLength(32)}},
+		{name: "negative bucket", opts: []Option{WithBucketSeconds(-60)}},
+		{name: "region too large", opts: []Option{WithRegion(16)}},
+		{name: "inverted time bounds", opts: []Option{WithTimeBounds(now, now.Add(-time.Hour))}},
+		{name: "invalid expected prefix", opts: []Option{WithExpectedPrefix("Order")}},
+	}
+	id := New("order")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewE("order", tt.opts...); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("NewE: expected ErrInvalidOption, got %v", err)
+			}
+			if _, err := ParseWith(id, tt.opts...); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("ParseWith: expected ErrInvalidOption, got %v", err)
+			}
+			if _, err := NewEncoder(tt.opts...); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("NewEncoder: expected ErrInvalidOption, got %v", err)
+			}
+			if _, err := NewDecoder(tt.opts...); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("NewDecoder: expected ErrInvalidOption, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package orderlyid
+
+import (
+	"fmt"
+	"time"
+)
+
+// IsPrivacyBucketed reports whether the ID's timestamp was rounded down to a
+// privacy bucket at generation time, for example via WithBucketSeconds. The
+// embedded time of a bucketed ID is only accurate to the bucket size.
+func (p *Parsed) IsPrivacyBucketed() bool {
+	return HasFlag(p, FlagPrivacy)
+}
+
+// IsBucketed is shorthand for IsPrivacyBucketed.
+func (p *Parsed) IsBucketed() bool {
+	return p.IsPrivacyBucketed()
+}
+
+// Version returns the wire version stored in flag bits 7..6. IDs in the v1
+// layout report version 0.
+func (p *Parsed) Version() uint8 {
+	return (p.Flags & FlagVersionMask) >> 6
+}
+
+// Bytes returns the packed 20-byte big-endian body of the ID. The body does
+// not include the prefix or checksum.
+func (p *Parsed) Bytes() [20]byte {
+	return pack(p.sinceEpoch(), p.Flags, p.Tenant, p.Seq&0x0FFF, p.Shard, p.Random&((1<<60)-1))
+}
+
+// RawBytes returns the packed 20-byte body of the ID, for debuggers and
+// re-encoders. It is the same as Bytes.
+func (p *Parsed) RawBytes() [20]byte {
+	return p.Bytes()
+}
+
+// sinceEpoch returns TimeMs as milliseconds since the epoch the ID was parsed
+// with, clamped at zero.
+func (p *Parsed) sinceEpoch() uint64 {
+	epoch := epoch2020 + p.epochShift
+	if p.TimeMs <= epoch {
+		return 0
+	}
+	return uint64(p.TimeMs - epoch)
+}
+
+// Components returns the parsed fields as Components, for a parse, modify,
+// and re-encode workflow:
+//
+//	c := p.Components()
+//	c.Tenant = 7
+//	id, err := orderlyid.NewFromParts(c, p.HasChecksum)
+//
+// NewFromParts(p.Components(), false) reproduces the canonical form of the
+// parsed ID without its checksum, except for IDs parsed by a Generator with a
+// custom epoch (see WithEpoch): NewFromParts counts TimeMs from 2020-01-01.
+func (p *Parsed) Components() Components {
+	return Components{
+		Prefix:   p.Prefix,
+		TimeMs:   p.TimeMs,
+		Flags:    p.Flags,
+		Tenant:   p.Tenant,
+		Seq:      p.Seq,
+		Shard:    p.Shard,
+		Random60: p.Random,
+	}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the 20-byte
+// body from Bytes. The binary form excludes the prefix and checksum; use
+// ParseBinary with the prefix to recover the ID, or SortKey for a binary form
+// that keeps the prefix.
+func (p *Parsed) MarshalBinary() ([]byte, error) {
+	body := p.Bytes()
+	return body[:], nil
+}
+
+// ParseBinary decodes a 20-byte body produced by MarshalBinary or Bytes and
+// attaches prefix to it. The result has HasChecksum set to false.
+//
+// ParseBinary returns an error wrapping ErrInvalidPayloadLength if b is not
+// 20 bytes long, or ErrInvalidPrefix if prefix is invalid.
+func ParseBinary(prefix string, b []byte) (*Parsed, error) {
+	if len(b) != 20 {
+		return nil, fmt.Errorf("%w: binary body must be 20 bytes, got %d", ErrInvalidPayloadLength, len(b))
+	}
+	if err := validatePrefix(prefix); err != nil {
+		return nil, err
+	}
+	return parsedFromBody(prefix, b), nil
+}
+
+// GobEncode implements gob.GobEncoder. The encoding is the 20-byte body from
+// Bytes, one byte holding ChecksumLen, and the prefix, rather than gob's
+// default field-by-field encoding.
+func (p *Parsed) GobEncode() ([]byte, error) {
+	n := 0
+	if p.HasChecksum {
+		n = max(p.ChecksumLen, 4)
+	}
+	body := p.Bytes()
+	b := make([]byte, 0, len(body)+1+len(p.Prefix))
+	b = append(b, body[:]...)
+	b = append(b, byte(n))
+	return append(b, p.Prefix...), nil
+}
+
+// GobDecode implements gob.GobDecoder for data produced by GobEncode.
+//
+// GobDecode returns an error wrapping ErrInvalidPayloadLength if b is too
+// short to hold a body, ErrInvalidChecksum if the checksum length is not 0, 4,
+// 6, or 8, or ErrInvalidPrefix if the prefix is invalid.
+func (p *Parsed) GobDecode(b []byte) error {
+	if len(b) < 21 {
+		return fmt.Errorf("%w: gob data must hold a 20-byte body, got %d bytes", ErrInvalidPayloadLength, len(b))
+	}
+	n := int(b[20])
+	if n != 0 && !validChecksumLen(n) {
+		return fmt.Errorf("%w: checksum length %d is not 4, 6, or 8", ErrInvalidChecksum, n)
+	}
+	prefix := string(b[21:])
+	if err := validatePrefix(prefix); err != nil {
+		return err
+	}
+	*p = *parsedFromBody(prefix, b[:20])
+	p.HasChecksum, p.ChecksumLen = n > 0, n
+	return nil
+}
+
+// parsedFromBody unpacks a 20-byte body into a Parsed with the given prefix.
+func parsedFromBody(prefix string, body []byte) *Parsed {
+	ms, flags, tenant, seq, shard, random60 := unpack(body)
+	return &Parsed{
+		Prefix: prefix,
+		TimeMs: int64(ms) + epoch2020,
+		Flags:  flags,
+		Tenant: tenant,
+		Seq:    seq,
+		Shard:  shard,
+		Random: random60,
+		Region: regionOf(flags, shard),
+	}
+}
+
+// OrderKey combines the timestamp and sequence into a single value,
+// (milliseconds since the epoch << 12) | seq, that orders IDs by time and
+// then by their position within the millisecond. The epoch is 2020-01-01
+// unless p was parsed by a Generator with WithEpoch. Keys of IDs from one
+// Generator with the same prefix, tenant, and shard increase strictly as long
+// as its clock does not move backwards.
+func (p *Parsed) OrderKey() uint64 {
+	return p.sinceEpoch()<<12 | uint64(p.Seq&0x0FFF)
+}
+
+// Time returns the embedded creation time as a UTC time.Time, accurate to the
+// millisecond. For privacy-bucketed IDs it is the start of the bucket.
+func (p *Parsed) Time() time.Time {
+	return time.UnixMilli(p.TimeMs).UTC()
+}
+
+// ExpiresAt returns the embedded creation time plus ttl. For privacy-bucketed
+// IDs the creation time is the start of the bucket, so the ID expires up to
+// one bucket earlier than a precise timestamp would.
+func (p *Parsed) ExpiresAt(ttl time.Duration) time.Time {
+	return p.Time().Add(ttl)
+}
+
+// Expired reports whether more than ttl has passed since the embedded creation
+// time, according to the system clock.
+func (p *Parsed) Expired(ttl time.Duration) bool {
+	return time.Now().After(p.ExpiresAt(ttl))
+}
+
+// RandomHex returns the 60-bit random field as 16 big-endian hex digits, the
+// form used by the random_hex field of the spec test vectors.
+func (p *Parsed) RandomHex() string {
+	return random60Hex(p.Random)
+}
+
+// String re-encodes the parsed fields into the canonical lowercase form,
+// appending a checksum of ChecksumLen characters (4 if unset) if HasChecksum
+// is set.
+//
+// String may return an error wrapping ErrInvalidPrefix if Prefix has been
+// changed to an invalid value, or ErrInvalidOption if ChecksumLen has been
+// changed to an unsupported length.
+func (p *Parsed) String() (string, error) {
+	if err := validatePrefix(p.Prefix); err != nil {
+		return "", err
+	}
+	n := 0
+	if p.HasChecksum {
+		n = max(p.ChecksumLen, 4)
+		if !validChecksumLen(n) {
+			return "", fmt.Errorf("%w: checksum length %d is not 4, 6, or 8", ErrInvalidOption, n)
+		}
+	}
+	return formatBody(p.Prefix, p.Bytes(), n), nil
+}
+
+// MustString is like String but panics on error.
+func (p *Parsed) MustString() string {
+	s, err := p.String()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// HasFlag reports whether all bits in f are set in p.Flags.
+func HasFlag(p *Parsed, f uint8) bool {
+	return p.Flags&f == f
+}
@@ -0,0 +1,331 @@
+package orderlyid
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIsPrivacyBucketed(t *testing.T) {
+	plain, err := Parse(New("event"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if plain.IsPrivacyBucketed() || plain.IsBucketed() {
+		t.Fatalf("unbucketed ID reports privacy bucketing")
+	}
+	if v := plain.Version(); v != 0 {
+		t.Fatalf("unbucketed ID reports version %d, want 0", v)
+	}
+
+	bucketed, err := Parse(New("event", WithBucketSeconds(60)))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !bucketed.IsPrivacyBucketed() || !bucketed.IsBucketed() {
+		t.Fatalf("bucketed ID does not report privacy bucketing")
+	}
+	if v := bucketed.Version(); v != 0 {
+		t.Fatalf("bucketed ID reports version %d, want 0", v)
+	}
+	if bucketed.TimeMs%60000 != 0 {
+		t.Fatalf("bucketed time not aligned to 60s: %d", bucketed.TimeMs)
+	}
+}
+
+func TestFlagConstantsMatchGeneratedIDs(t *testing.T) {
+	masks := []uint8{FlagVersionMask, FlagPrivacy, FlagRegion, FlagApplicationMask}
+	var all uint8
+	for _, m := range masks {
+		if all&m != 0 {
+			t.Fatalf("flag mask 0x%02x overlaps 0x%02x", m, all)
+		}
+		all |= m
+	}
+	if all != 0xFF {
+		t.Fatalf("flag masks do not cover the flags byte")
+	}
+
+	bucketed, err := Parse(New("event", WithBucketSeconds(1)))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if bucketed.Flags != FlagPrivacy || !HasFlag(bucketed, FlagPrivacy) {
+		t.Fatalf("WithBucketSeconds set flags 0x%02x, want 0x%02x", bucketed.Flags, FlagPrivacy)
+	}
+
+	plain, err := Parse(New("event"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if plain.Flags != 0 || HasFlag(plain, FlagPrivacy) {
+		t.Fatalf("New set unexpected flags 0x%02x", plain.Flags)
+	}
+
+	body := pack(0, FlagPrivacy, 0, 0, 0, 0)
+	if body[6] != FlagPrivacy {
+		t.Fatalf("pack stored flags 0x%02x, want 0x%02x", body[6], FlagPrivacy)
+	}
+}
+
+func TestWithFlags(t *testing.T) {
+	p := mustParse(t, New("order", WithFlags(0x01), WithFlags(0x08), WithBucketSeconds(60), WithRegion(2)))
+	if want := FlagPrivacy | FlagRegion | 0x09; p.Flags != want {
+		t.Fatalf("Flags = 0x%02x, want 0x%02x", p.Flags, want)
+	}
+	if p.Flags&FlagApplicationMask != 0x09 || p.Version() != 0 {
+		t.Fatalf("application bits = 0x%02x, version %d", p.Flags&FlagApplicationMask, p.Version())
+	}
+
+	for _, mask := range []uint8{0x40, 0x80, FlagPrivacy, FlagRegion, 0x11} {
+		if _, err := NewE("order", WithFlags(mask)); !errors.Is(err, ErrInvalidOption) {
+			t.Fatalf("WithFlags(0x%02x): expected ErrInvalidOption, got %v", mask, err)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	p, err := Parse(New("order", WithBucketSeconds(60)))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if v := p.Version(); v != 0 {
+		t.Fatalf("v1 ID reports version %d, want 0", v)
+	}
+	if v := (&Parsed{Flags: 0xC0 | FlagPrivacy}).Version(); v != 3 {
+		t.Fatalf("flags 0xe0 report version %d, want 3", v)
+	}
+}
+
+func TestUnsupportedVersionRejected(t *testing.T) {
+	id, err := NewFromParts(Components{Prefix: "order", TimeMs: 1735689600000, Flags: 0xC0, Random60: 1}, true)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	if _, err := Parse(id); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("Parse: expected ErrUnsupportedVersion, got %v", err)
+	}
+	var dst Parsed
+	if err := ParseInto(id, &dst); !errors.Is(err, ErrUnsupportedVersion) || dst != (Parsed{}) {
+		t.Fatalf("ParseInto = %+v, %v; want untouched dst and ErrUnsupportedVersion", dst, err)
+	}
+	if _, err := ParseWithMaxVersion(id, 2); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("ParseWithMaxVersion(2): expected ErrUnsupportedVersion, got %v", err)
+	}
+	p, err := ParseWithMaxVersion(id, 3)
+	if err != nil {
+		t.Fatalf("ParseWithMaxVersion(3): %v", err)
+	}
+	if p.Version() != 3 || p.Random != 1 {
+		t.Fatalf("unexpected parse result: %+v", p)
+	}
+}
+
+func TestBytesRoundTrip(t *testing.T) {
+	for _, id := range []string{
+		New("order", WithTenant(65535), WithShard(0xABCD), WithChecksum(true)),
+		New("user", WithBucketSeconds(3600)),
+	} {
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse %s: %v", id, err)
+		}
+		body := p.Bytes()
+		ms, flags, tenant, seq, shard, random := unpack(body[:])
+		if int64(ms)+epoch2020 != p.TimeMs || flags != p.Flags || tenant != p.Tenant ||
+			seq != p.Seq || shard != p.Shard || random != p.Random {
+			t.Fatalf("Bytes round trip mismatch for %s: %+v", id, p)
+		}
+		if got := b32encode(body[:]); got != id[len(p.Prefix)+1:len(p.Prefix)+1+32] {
+			t.Fatalf("Bytes encodes to %s, want payload of %s", got, id)
+		}
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, id := range []string{
+		New("order", WithTenant(65535), WithShard(0xABCD), WithChecksum(true)),
+		New("user", WithRegion(7), WithBucketSeconds(60)),
+	} {
+		p := mustParse(t, id)
+		b, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+		if len(b) != 20 {
+			t.Fatalf("len = %d, want 20", len(b))
+		}
+		got, err := ParseBinary(p.Prefix, b)
+		if err != nil {
+			t.Fatalf("ParseBinary: %v", err)
+		}
+		want := *p
+		want.HasChecksum, want.ChecksumLen = false, 0
+		if *got != want {
+			t.Fatalf("ParseBinary = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestParseBinaryErrors(t *testing.T) {
+	if _, err := ParseBinary("order", make([]byte, 19)); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+	if _, err := ParseBinary("Order", make([]byte, 20)); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	type cached struct {
+		Key string
+		ID  Parsed
+	}
+	var in []cached
+	for i, id := range []string{
+		New("order", WithTenant(65535), WithShard(0xABCD)),
+		New("user", WithRegion(5), WithChecksum(true)),
+		New("invoice", WithChecksumLen(8)),
+	} {
+		in = append(in, cached{Key: fmt.Sprint(i), ID: *mustParse(t, id)})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var out []cached
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("decoded %d entries, want %d", len(out), len(in))
+	}
+	for i := range in {
+		if out[i] != in[i] {
+			t.Fatalf("entry %d = %+v, want %+v", i, out[i], in[i])
+		}
+	}
+
+	enc, err := in[0].ID.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode: %v", err)
+	}
+	if want := 20 + 1 + len("order"); len(enc) != want {
+		t.Fatalf("GobEncode length = %d, want %d", len(enc), want)
+	}
+}
+
+func TestGobDecodeErrors(t *testing.T) {
+	enc, err := mustParse(t, New("order", WithChecksum(true))).GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode: %v", err)
+	}
+	badLen := bytes.Clone(enc)
+	badLen[20] = 5
+	badPrefix := append(bytes.Clone(enc[:21]), "Order"...)
+	tests := []struct {
+		name string
+		in   []byte
+		want error
+	}{
+		{name: "short", in: enc[:20], want: ErrInvalidPayloadLength},
+		{name: "checksum length", in: badLen, want: ErrInvalidChecksum},
+		{name: "prefix", in: badPrefix, want: ErrInvalidPrefix},
+		{name: "missing prefix", in: enc[:21], want: ErrInvalidPrefix},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Parsed
+			if err := p.GobDecode(tt.in); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParsedStringReproducesCanonical(t *testing.T) {
+	for _, id := range []string{
+		New("order", WithTenant(1), WithShard(2)),
+		New("order", WithChecksum(true)),
+	} {
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		got, err := p.String()
+		if err != nil {
+			t.Fatalf("String: %v", err)
+		}
+		if got != id {
+			t.Fatalf("String = %s, want %s", got, id)
+		}
+		if p.MustString() != id {
+			t.Fatalf("MustString = %s, want %s", p.MustString(), id)
+		}
+	}
+
+	upper := "ORDER"
+	p, err := Parse("order_00JC1GMM00000000000028T5CY4TQKFF")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := p.MustString(); got != "order_00jc1gmm00000000000028t5cy4tqkff" {
+		t.Fatalf("String did not canonicalize case: %s", got)
+	}
+	p.Prefix = upper
+	if _, err := p.String(); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+}
+
+func TestOrderKeyIncreasesWithinMillisecond(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewGenerator(WithClock(steppingClock(base, base, base, base, base.Add(time.Millisecond))))
+	var prev uint64
+	for i := 0; i < 5; i++ {
+		p := mustParse(t, g.New("order", WithShard(7)))
+		k := p.OrderKey()
+		if want := uint64(p.TimeMs-epoch2020)<<12 | uint64(p.Seq); k != want {
+			t.Fatalf("OrderKey = %#x, want %#x", k, want)
+		}
+		if i > 0 && k <= prev {
+			t.Fatalf("call %d: OrderKey %#x not above %#x", i, k, prev)
+		}
+		prev = k
+	}
+}
+
+func TestTime(t *testing.T) {
+	at := time.Date(2025, 3, 4, 5, 6, 7, 891e6, time.FixedZone("CET", 3600))
+	p := mustParse(t, New("order", WithTime(at)))
+	got := p.Time()
+	if !got.Equal(at) || got.Location() != time.UTC {
+		t.Fatalf("Time = %v, want %v in UTC", got, at)
+	}
+	if got.UnixMilli() != p.TimeMs {
+		t.Fatalf("Time().UnixMilli() = %d, want %d", got.UnixMilli(), p.TimeMs)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := mustParse(t, NewGenerator(WithClock(fixedClock(created))).New("idem"))
+	if got, want := old.ExpiresAt(time.Hour), created.Add(time.Hour); !got.Equal(want) {
+		t.Fatalf("ExpiresAt = %s, want %s", got, want)
+	}
+	if !old.Expired(time.Hour) {
+		t.Fatalf("old ID not expired after 1h TTL")
+	}
+
+	fresh := mustParse(t, New("idem"))
+	if fresh.Expired(time.Hour) {
+		t.Fatalf("fresh ID expired under 1h TTL")
+	}
+	if !fresh.Expired(-time.Second) {
+		t.Fatalf("fresh ID not expired under a negative TTL")
+	}
+}
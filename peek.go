@@ -0,0 +1,85 @@
+package orderlyid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrefixOf returns the prefix of s without decoding the payload. Surrounding
+// whitespace is ignored, as in Parse. The payload and checksum are not
+// checked, so a nil error does not mean that s parses.
+//
+// PrefixOf returns an error wrapping ErrInvalidFormat if s has no "_"
+// separator, or ErrInvalidPrefix if the prefix is invalid.
+func PrefixOf(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, '_')
+	if i < 0 {
+		return "", fmt.Errorf("%w: missing '_' separator", ErrInvalidFormat)
+	}
+	if err := validatePrefix(s[:i]); err != nil {
+		return "", err
+	}
+	return s[:i], nil
+}
+
+// PayloadOf returns the 32-character payload of s as written, between the
+// prefix separator and any checksum suffix, for callers that re-encode or
+// inspect it. Surrounding whitespace is ignored, as in Parse. The payload's
+// symbols are checked but the checksum is not verified, and the payload is
+// not canonicalized; decode it with Parse to get the 20-byte body from
+// Parsed.Bytes.
+//
+// PayloadOf may return an error wrapping ErrInvalidFormat or ErrInvalidPrefix
+// as PrefixOf does, ErrInvalidPayloadLength if the payload is not 32 symbols
+// long, or ErrInvalidBase32 if one of its symbols is invalid.
+func PayloadOf(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	prefix, err := PrefixOf(s)
+	if err != nil {
+		return "", err
+	}
+	payload := s[len(prefix)+1:]
+	if i := strings.LastIndexByte(payload, '-'); i >= 0 {
+		payload = payload[:i]
+	}
+	if len(payload) != 32 {
+		return "", fmt.Errorf("%w: must be 32 chars", ErrInvalidPayloadLength)
+	}
+	for i := 0; i < len(payload); i++ {
+		if alphaRev[payload[i]] == 0xFF {
+			return "", fmt.Errorf("%w: invalid character at pos %d", ErrInvalidBase32, i)
+		}
+	}
+	return payload, nil
+}
+
+// TimeOf returns the embedded timestamp of s in Unix milliseconds, decoding
+// only the first ten payload symbols that carry the 48-bit time field. The
+// rest of the payload and the checksum are not checked; use Parse when s
+// comes from an untrusted source and must be validated.
+//
+// TimeOf may return an error wrapping ErrInvalidFormat or ErrInvalidPrefix as
+// PrefixOf does, ErrInvalidPayloadLength if the payload is shorter than ten
+// symbols, or ErrInvalidBase32 if one of those symbols is invalid.
+func TimeOf(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	prefix, err := PrefixOf(s)
+	if err != nil {
+		return 0, err
+	}
+	payload := s[len(prefix)+1:]
+	if len(payload) < 10 {
+		return 0, fmt.Errorf("%w: must be 32 chars", ErrInvalidPayloadLength)
+	}
+	var acc uint64
+	for i := 0; i < 10; i++ {
+		v := alphaRev[payload[i]]
+		if v == 0xFF {
+			return 0, fmt.Errorf("%w: invalid character at pos %d", ErrInvalidBase32, i)
+		}
+		acc = acc<<5 | uint64(v)
+	}
+	// Ten symbols carry 50 bits; the time field is the top 48.
+	return int64(acc>>2) + epoch2020, nil
+}
@@ -0,0 +1,128 @@
+package orderlyid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPrefixOf(t *testing.T) {
+	id := New("order", WithChecksum(true))
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", in: id, want: "order"},
+		{name: "whitespace", in: " " + id + "\n", want: "order"},
+		{name: "payload not checked", in: "order_x", want: "order"},
+		{name: "no separator", in: "order", wantErr: ErrInvalidFormat},
+		{name: "bad prefix", in: "Order_x", wantErr: ErrInvalidPrefix},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrefixOf(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PrefixOf: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("PrefixOf = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeOfMatchesParse(t *testing.T) {
+	for _, at := range []time.Time{
+		time.UnixMilli(epoch2020),
+		time.Date(2025, 1, 1, 12, 34, 56, 789e6, time.UTC),
+		time.UnixMilli(epoch2020 + 1<<48 - 1),
+	} {
+		id := New("order", WithTime(at), WithAllowFuture(), WithChecksum(true))
+		got, err := TimeOf(id)
+		if err != nil {
+			t.Fatalf("TimeOf(%s): %v", id, err)
+		}
+		if want := mustParse(t, id).TimeMs; got != want {
+			t.Fatalf("TimeOf(%s) = %d, want %d", id, got, want)
+		}
+	}
+
+	if _, err := TimeOf("order_123"); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+	if _, err := TimeOf("order_00000000!0"); !errors.Is(err, ErrInvalidBase32) {
+		t.Fatalf("expected ErrInvalidBase32, got %v", err)
+	}
+}
+
+func TestPayloadOf(t *testing.T) {
+	id := New("order", WithTenant(7), WithShard(9), WithChecksumLen(6))
+	p := mustParse(t, id)
+	body := p.RawBytes()
+	if want := pack(uint64(p.TimeMs-epoch2020), p.Flags, p.Tenant, p.Seq, p.Shard, p.Random); body != want {
+		t.Fatalf("RawBytes = %x, want fresh pack %x", body, want)
+	}
+	if body != p.Bytes() {
+		t.Fatalf("RawBytes = %x, want Bytes %x", body, p.Bytes())
+	}
+	want := b32encode(body[:])
+
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr error
+	}{
+		{name: "checksummed", in: id, want: want},
+		{name: "plain", in: baseOf(id), want: want},
+		{name: "whitespace", in: "\t" + id + " ", want: want},
+		{name: "no separator", in: "order", wantErr: ErrInvalidFormat},
+		{name: "short", in: "order_" + want[:31], wantErr: ErrInvalidPayloadLength},
+		{name: "bad symbol", in: "order_" + want[:31] + "!", wantErr: ErrInvalidBase32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PayloadOf(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PayloadOf: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("PayloadOf = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkPrefixOf(b *testing.B) {
+	id := New("order", WithChecksum(true))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := PrefixOf(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTimeOf(b *testing.B) {
+	id := New("order", WithChecksum(true))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := TimeOf(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
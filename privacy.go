@@ -0,0 +1,55 @@
+package orderlyid
+
+// PrivacyLevel names a granularity to which generated timestamps are
+// truncated. It is a named alternative to WithBucketSeconds.
+type PrivacyLevel int
+
+const (
+	// PrivacyNone keeps millisecond timestamps and leaves the privacy flag
+	// clear.
+	PrivacyNone PrivacyLevel = iota
+	// PrivacyMinute truncates timestamps to the minute.
+	PrivacyMinute
+	// PrivacyHour truncates timestamps to the hour.
+	PrivacyHour
+	// PrivacyDay truncates timestamps to the UTC day.
+	PrivacyDay
+)
+
+// String returns a short human-readable name for the level.
+func (l PrivacyLevel) String() string {
+	switch l {
+	case PrivacyNone:
+		return "none"
+	case PrivacyMinute:
+		return "minute"
+	case PrivacyHour:
+		return "hour"
+	case PrivacyDay:
+		return "day"
+	default:
+		return "unknown"
+	}
+}
+
+// BucketSeconds returns the bucket size used for the level, or 0 for
+// PrivacyNone and unknown levels.
+func (l PrivacyLevel) BucketSeconds() int {
+	switch l {
+	case PrivacyMinute:
+		return 60
+	case PrivacyHour:
+		return 60 * 60
+	case PrivacyDay:
+		return 24 * 60 * 60
+	default:
+		return 0
+	}
+}
+
+// WithPrivacyLevel truncates the embedded timestamp to the granularity of l
+// and sets FlagPrivacy, exactly like WithBucketSeconds(l.BucketSeconds()).
+// PrivacyNone disables truncation.
+func WithPrivacyLevel(l PrivacyLevel) Option {
+	return WithBucketSeconds(l.BucketSeconds())
+}
@@ -0,0 +1,41 @@
+package orderlyid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithPrivacyLevelTruncates(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 9, 26, 535_000_000, time.UTC)
+	g := NewGenerator(WithClock(fixedClock(now)))
+
+	tests := []struct {
+		level   PrivacyLevel
+		name    string
+		want    time.Time
+		flagged bool
+	}{
+		{level: PrivacyNone, name: "none", want: now},
+		{level: PrivacyMinute, name: "minute", want: time.Date(2025, 3, 14, 15, 9, 0, 0, time.UTC), flagged: true},
+		{level: PrivacyHour, name: "hour", want: time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC), flagged: true},
+		{level: PrivacyDay, name: "day", want: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), flagged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.level.String(); got != tt.name {
+				t.Fatalf("String = %q, want %q", got, tt.name)
+			}
+			p := mustParse(t, g.New("event", WithPrivacyLevel(tt.level)))
+			if p.TimeMs != tt.want.UnixMilli() {
+				t.Fatalf("time = %s, want %s", p.Time(), tt.want)
+			}
+			if p.IsPrivacyBucketed() != tt.flagged {
+				t.Fatalf("IsPrivacyBucketed = %v, want %v", p.IsPrivacyBucketed(), tt.flagged)
+			}
+		})
+	}
+
+	if got := PrivacyLevel(42).String(); got != "unknown" {
+		t.Fatalf("String = %q, want unknown", got)
+	}
+}
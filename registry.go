@@ -0,0 +1,88 @@
+package orderlyid
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]struct{}{}
+)
+
+// RegisterPrefix adds p to the package-level set of known prefixes consulted
+// by WithRequireRegistered. It is typically called from init functions or at
+// service startup. Registering the same prefix twice is a no-op.
+//
+// RegisterPrefix panics if p does not satisfy the prefix rule.
+func RegisterPrefix(p string) {
+	if err := validatePrefix(p); err != nil {
+		panic(err)
+	}
+	registryMu.Lock()
+	registry[p] = struct{}{}
+	registryMu.Unlock()
+}
+
+// KnownPrefixes returns the registered prefixes in sorted order.
+func KnownPrefixes() []string {
+	registryMu.RLock()
+	out := make([]string, 0, len(registry))
+	for p := range registry {
+		out = append(out, p)
+	}
+	registryMu.RUnlock()
+	slices.Sort(out)
+	return out
+}
+
+// confusables maps look-alike character sequences to a common skeleton. It
+// covers the prefix alphabet [a-z0-9]; multi-character entries come first so
+// they are replaced before their parts.
+var confusables = strings.NewReplacer(
+	"rn", "m",
+	"vv", "w",
+	"cl", "d",
+	"0", "o",
+	"1", "l",
+	"i", "l",
+	"j", "l",
+	"2", "z",
+	"5", "s",
+	"6", "b",
+	"8", "b",
+	"9", "q",
+	"g", "q",
+	"u", "v",
+)
+
+// skeleton reduces p to a form in which visually confusable prefixes are
+// equal.
+func skeleton(p string) string {
+	return confusables.Replace(p)
+}
+
+// confusableWith returns a registered prefix other than p that looks like p,
+// or "" if there is none or p is registered itself.
+func confusableWith(p string) string {
+	sk := skeleton(p)
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if _, ok := registry[p]; ok {
+		return ""
+	}
+	for r := range registry {
+		if skeleton(r) == sk {
+			return r
+		}
+	}
+	return ""
+}
+
+func isRegistered(p string) bool {
+	registryMu.RLock()
+	_, ok := registry[p]
+	registryMu.RUnlock()
+	return ok
+}
@@ -0,0 +1,105 @@
+package orderlyid
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRequireRegistered(t *testing.T) {
+	RegisterPrefix("regorder")
+	RegisterPrefix("regorder")
+	RegisterPrefix("reguser")
+
+	known := KnownPrefixes()
+	if !slices.Contains(known, "regorder") || !slices.Contains(known, "reguser") {
+		t.Fatalf("KnownPrefixes missing registrations: %v", known)
+	}
+	if !slices.IsSorted(known) {
+		t.Fatalf("KnownPrefixes not sorted: %v", known)
+	}
+
+	id := New("regorder", WithRequireRegistered())
+	if _, err := ParseWith(id, WithRequireRegistered()); err != nil {
+		t.Fatalf("ParseWith registered: %v", err)
+	}
+
+	unregistered := New("regmissing")
+	if _, err := ParseWith(unregistered); err != nil {
+		t.Fatalf("ParseWith without option: %v", err)
+	}
+	if _, err := ParseWith(unregistered, WithRequireRegistered()); !errors.Is(err, ErrUnregisteredPrefix) {
+		t.Fatalf("expected ErrUnregisteredPrefix, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic for unregistered prefix")
+		}
+	}()
+	_ = New("regmissing", WithRequireRegistered())
+}
+
+func TestRegisterPrefixRejectsInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on invalid prefix")
+		}
+	}()
+	RegisterPrefix("Bad!")
+}
+
+func TestConfusableCheck(t *testing.T) {
+	RegisterPrefix("invoice")
+	RegisterPrefix("merchant")
+
+	if _, err := NewE("invoice", WithConfusableCheck()); err != nil {
+		t.Fatalf("registered prefix rejected: %v", err)
+	}
+	if _, err := NewE("shipment", WithConfusableCheck()); err != nil {
+		t.Fatalf("unrelated prefix rejected: %v", err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   error
+	}{
+		{prefix: "inv0ice", want: ErrConfusablePrefix},
+		{prefix: "lnvoice", want: ErrConfusablePrefix},
+		{prefix: "invo1ce", want: ErrConfusablePrefix},
+		{prefix: "rnerchant", want: ErrConfusablePrefix},
+		{prefix: "merchamt"}, // "am" is not in the look-alike table
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if _, err := NewE(tt.prefix, WithConfusableCheck()); !errors.Is(err, tt.want) {
+				t.Fatalf("NewE: error = %v, want %v", err, tt.want)
+			}
+			id := New(tt.prefix)
+			if _, err := ParseWith(id, WithConfusableCheck()); !errors.Is(err, tt.want) {
+				t.Fatalf("ParseWith: error = %v, want %v", err, tt.want)
+			}
+			if _, err := ParseWith(id); err != nil {
+				t.Fatalf("ParseWith without option rejected %s: %v", id, err)
+			}
+		})
+	}
+}
+
+func TestConfusableCheckRegisteredLookAlikes(t *testing.T) {
+	// Both prefixes are registered, so neither is rejected for looking like
+	// the other, while an unregistered third look-alike still is.
+	RegisterPrefix("metric")
+	RegisterPrefix("rnetric")
+	for _, p := range []string{"metric", "rnetric"} {
+		if _, err := NewE(p, WithConfusableCheck()); err != nil {
+			t.Fatalf("NewE(%q): %v", p, err)
+		}
+		if _, err := ParseWith(New(p), WithConfusableCheck()); err != nil {
+			t.Fatalf("ParseWith(%q): %v", p, err)
+		}
+	}
+	if _, err := NewE("rnetr1c", WithConfusableCheck()); !errors.Is(err, ErrConfusablePrefix) {
+		t.Fatalf("NewE(rnetr1c): error = %v, want ErrConfusablePrefix", err)
+	}
+}
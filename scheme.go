@@ -0,0 +1,139 @@
+package orderlyid
+
+import "strings"
+
+// Scheme identifies the family of an identifier token.
+type Scheme int
+
+const (
+	// SchemeUnknown reports tokens that match none of the known shapes.
+	SchemeUnknown Scheme = iota
+	// SchemeOrderlyID reports "prefix_payload[-checksum]" tokens.
+	SchemeOrderlyID
+	// SchemeUUID reports RFC 4122 UUIDs in hyphenated or bare hex form.
+	SchemeUUID
+	// SchemeULID reports 26-character Crockford Base32 ULIDs.
+	SchemeULID
+	// SchemeKSUID reports 27-character Base62 KSUIDs.
+	SchemeKSUID
+)
+
+// String returns a short human-readable name for the scheme.
+func (s Scheme) String() string {
+	switch s {
+	case SchemeOrderlyID:
+		return "orderlyid"
+	case SchemeUUID:
+		return "uuid"
+	case SchemeULID:
+		return "ulid"
+	case SchemeKSUID:
+		return "ksuid"
+	default:
+		return "unknown"
+	}
+}
+
+// DetectScheme classifies s by shape, length, and alphabet.
+//
+// DetectScheme does not decode the token or verify checksums; a result of
+// SchemeOrderlyID only means s looks like an OrderlyID. Use Parse to validate.
+func DetectScheme(s string) Scheme {
+	s = strings.TrimSpace(s)
+	switch {
+	case looksLikeOrderlyID(s):
+		return SchemeOrderlyID
+	case looksLikeUUID(s):
+		return SchemeUUID
+	case looksLikeULID(s):
+		return SchemeULID
+	case looksLikeKSUID(s):
+		return SchemeKSUID
+	}
+	return SchemeUnknown
+}
+
+func looksLikeOrderlyID(s string) bool {
+	i := strings.IndexByte(s, '_')
+	if i <= 0 || !prefixRe.MatchString(s[:i]) {
+		return false
+	}
+	rest := s[i+1:]
+	switch {
+	case len(rest) == 32:
+	case len(rest) > 33 && validChecksumLen(len(rest)-33) && rest[32] == '-':
+		rest = rest[:32] + rest[33:]
+	default:
+		return false
+	}
+	for j := 0; j < len(rest); j++ {
+		if !alphaValidMask[rest[j]] {
+			return false
+		}
+	}
+	return true
+}
+
+func looksLikeUUID(s string) bool {
+	switch len(s) {
+	case 36:
+		for i := 0; i < len(s); i++ {
+			switch i {
+			case 8, 13, 18, 23:
+				if s[i] != '-' {
+					return false
+				}
+			default:
+				if !isHex(s[i]) {
+					return false
+				}
+			}
+		}
+		return true
+	case 32:
+		for i := 0; i < len(s); i++ {
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func looksLikeULID(s string) bool {
+	if len(s) != 26 {
+		return false
+	}
+	// A 128-bit ULID leaves only 3 bits for the first symbol.
+	if s[0] < '0' || s[0] > '7' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if strings.IndexByte(string(alpha), c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func looksLikeKSUID(s string) bool {
+	if len(s) != 27 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
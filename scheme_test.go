@@ -0,0 +1,35 @@
+package orderlyid
+
+import "testing"
+
+func TestDetectScheme(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Scheme
+	}{
+		{name: "orderlyid", in: New("order"), want: SchemeOrderlyID},
+		{name: "orderlyid checksum", in: New("order", WithChecksum(true)), want: SchemeOrderlyID},
+		{name: "orderlyid uppercase payload", in: "order_00JC1GMM00000000000028T5CY4TQKFF", want: SchemeOrderlyID},
+		{name: "uuid", in: "550e8400-e29b-41d4-a716-446655440000", want: SchemeUUID},
+		{name: "uuid uppercase", in: "550E8400-E29B-41D4-A716-446655440000", want: SchemeUUID},
+		{name: "uuid bare hex", in: "550e8400e29b41d4a716446655440000", want: SchemeUUID},
+		{name: "ulid", in: "01ARZ3NDEKTSV4RRFFQ69G5FAV", want: SchemeULID},
+		{name: "ulid lowercase", in: "01arz3ndektsv4rrffq69g5fav", want: SchemeULID},
+		{name: "ksuid", in: "0ujtsYcgvSTl8PAuAdqWYSMnLOv", want: SchemeKSUID},
+		{name: "empty", in: "", want: SchemeUnknown},
+		{name: "bad prefix", in: "Order_00jc1gmm00000000000028t5cy4tqkff", want: SchemeUnknown},
+		{name: "short payload", in: "order_00jc1gmm", want: SchemeUnknown},
+		{name: "uuid misplaced dash", in: "550e8400e-29b-41d4-a716-446655440000", want: SchemeUnknown},
+		{name: "ulid overflow", in: "81ARZ3NDEKTSV4RRFFQ69G5FAV", want: SchemeUnknown},
+		{name: "ksuid bad char", in: "0ujtsYcgvSTl8PAuAdqWYSMnLO!", want: SchemeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectScheme(tt.in); got != tt.want {
+				t.Fatalf("DetectScheme(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
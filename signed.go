@@ -0,0 +1,73 @@
+package orderlyid
+
+import (
+	"crypto/ed25519"
+	"encoding/base32"
+	"fmt"
+	"strings"
+)
+
+// sigEncoding encodes signatures with the same Crockford alphabet as payloads.
+var sigEncoding = base32.NewEncoding(string(alpha)).WithPadding(base32.NoPadding)
+
+// signatureLen is the encoded length of an Ed25519 signature.
+var signatureLen = sigEncoding.EncodedLen(ed25519.SignatureSize)
+
+// SignedNew generates a new ID like New and appends an Ed25519 signature made
+// with priv, as in "order_<payload>.<signature>". Holders of the matching
+// public key can check with VerifySigned that the ID was minted by the key
+// owner, which a checksum cannot provide.
+//
+// Ed25519 signatures cannot be truncated and still verified, so the full
+// 64-byte signature is kept: it adds 104 characters (a "." and 103 Base32
+// symbols) to the ID. The signature covers the lowercase "prefix_payload"
+// base. Display options such as WithUppercaseOutput and WithGroupingDashes are
+// ignored; WithChecksum is honored and the checksum precedes the signature.
+//
+// SignedNew panics under the same conditions as New, or if priv is not a
+// valid Ed25519 private key.
+func SignedNew(priv ed25519.PrivateKey, prefix string, opts ...Option) string {
+	if len(priv) != ed25519.PrivateKeySize {
+		panic(fmt.Sprintf("orderlyid: SignedNew: bad private key length %d", len(priv)))
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.uppercase = false
+		o.groupSize = 0
+	})
+	id := New(prefix, opts...)
+	sig := ed25519.Sign(priv, []byte(baseOf(id)))
+	return id + "." + sigEncoding.EncodeToString(sig)
+}
+
+// VerifySigned parses an ID produced by SignedNew and verifies its signature
+// with pub. Payload and signature are accepted in either case.
+//
+// VerifySigned returns an error wrapping ErrInvalidSignature if the signature
+// is missing, malformed, made with a different key, or does not match the ID,
+// and otherwise any error returned by Parse.
+func VerifySigned(pub ed25519.PublicKey, id string) (*Parsed, error) {
+	id = strings.TrimSpace(id)
+	i := strings.LastIndexByte(id, '.')
+	if i < 0 {
+		return nil, fmt.Errorf("%w: missing signature", ErrInvalidSignature)
+	}
+	unsigned, enc := id[:i], id[i+1:]
+	p, err := Parse(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) != signatureLen {
+		return nil, fmt.Errorf("%w: must be %d chars", ErrInvalidSignature, signatureLen)
+	}
+	sig, err := sigEncoding.DecodeString(strings.ToLower(enc))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: bad public key length %d", ErrInvalidSignature, len(pub))
+	}
+	if !ed25519.Verify(pub, []byte(strings.ToLower(baseOf(unsigned))), sig) {
+		return nil, fmt.Errorf("%w: verification failed", ErrInvalidSignature)
+	}
+	return p, nil
+}
@@ -0,0 +1,90 @@
+package orderlyid
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestSortKeyOrderMatchesStringOrder(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	ids := make([]string, 500)
+	for i := range ids {
+		prefix := "order"
+		if i%5 == 0 {
+			prefix = "ord"
+		}
+		id, err := NewFromParts(Components{
+			Prefix:   prefix,
+			TimeMs:   1735689600000 + r.Int63n(1_000_000),
+			Flags:    uint8(r.Intn(64)), // version 0 only
+			Tenant:   uint16(r.Intn(65536)),
+			Seq:      uint16(r.Intn(4096)),
+			Shard:    uint16(r.Intn(65536)),
+			Random60: r.Uint64(),
+		}, r.Intn(2) == 0)
+		if err != nil {
+			t.Fatalf("NewFromParts: %v", err)
+		}
+		ids[i] = id
+	}
+	keys := make([][]byte, len(ids))
+	for i, id := range ids {
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		keys[i] = p.SortKey()
+	}
+	for i := range ids {
+		for j := range ids {
+			if (i%5 == 0) != (j%5 == 0) {
+				continue // different prefixes
+			}
+			want := strings.Compare(baseOf(ids[i]), baseOf(ids[j]))
+			if got := bytes.Compare(keys[i], keys[j]); got != want {
+				t.Fatalf("order mismatch for %s vs %s: bytes %d, strings %d", ids[i], ids[j], got, want)
+			}
+		}
+	}
+}
+
+func TestBinaryIDScanValue(t *testing.T) {
+	id := New("order", WithTenant(11), WithChecksum(true))
+	v, err := BinaryID(id).Value()
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+	key, ok := v.([]byte)
+	if !ok || len(key) != len("order")+1+20 {
+		t.Fatalf("Value = %#v, want a %d-byte key", v, len("order")+21)
+	}
+	var back BinaryID
+	if err := back.Scan(key); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if want := baseOf(id); string(back) != want {
+		t.Fatalf("Scan = %s, want %s", back, want)
+	}
+
+	if _, err := BinaryID("").Value(); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("empty Value: expected ErrInvalidFormat, got %v", err)
+	}
+	if err := back.Scan(nil); !errors.Is(err, ErrInvalidFormat) || string(back) != baseOf(id) {
+		t.Fatalf("Scan(nil) = %q, %v; want ErrInvalidFormat and no change", back, err)
+	}
+	if _, err := BinaryID("order_123").Value(); !errors.Is(err, ErrInvalidPayloadLength) {
+		t.Fatalf("expected ErrInvalidPayloadLength, got %v", err)
+	}
+	if err := back.Scan([]byte("order")); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+	if err := back.Scan(append([]byte("Bad\x00"), make([]byte, 20)...)); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+	if err := back.Scan(42); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
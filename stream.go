@@ -0,0 +1,114 @@
+package orderlyid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report summarizes a stream checked by ValidateStream.
+type Report struct {
+	// Lines is the number of non-blank lines read.
+	Lines int
+	// Valid is the number of lines that parsed as IDs.
+	Valid int
+	// Invalid is the number of lines that failed to parse.
+	Invalid int
+	// WithChecksum is the number of valid IDs that carried a checksum.
+	WithChecksum int
+	// MinTime and MaxTime bound the embedded times of the valid IDs. Both
+	// are zero if there were none.
+	MinTime, MaxTime time.Time
+}
+
+// ChecksumCoverage returns the fraction of valid IDs that carried a
+// checksum, or 0 if there were no valid IDs.
+func (r Report) ChecksumCoverage() float64 {
+	if r.Valid == 0 {
+		return 0
+	}
+	return float64(r.WithChecksum) / float64(r.Valid)
+}
+
+// maxStreamLine is the longest line ValidateStream examines; longer lines
+// are invalid and reported truncated to this length.
+const maxStreamLine = bufio.MaxScanTokenSize
+
+// ValidateStream reads newline-delimited IDs from r and parses each with
+// Parse, verifying checksums where present. Surrounding whitespace is ignored
+// and blank lines are skipped. Each invalid line is written to w as
+//
+//	<line number>: <line>: <reason>
+//
+// Lines longer than 64 KiB are invalid, with an error wrapping
+// ErrInvalidFormat, and are written truncated with a trailing "...".
+//
+// ValidateStream returns the summary of everything read so far together with
+// the first error from reading r or writing w.
+func ValidateStream(r io.Reader, w io.Writer) (Report, error) {
+	var rep Report
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		raw, long, err := readLine(br, maxStreamLine)
+		if err == io.EOF {
+			return rep, nil
+		}
+		if err != nil {
+			return rep, err
+		}
+		line := strings.TrimSpace(string(raw))
+		if line == "" && !long {
+			continue
+		}
+		rep.Lines++
+		var p Parsed
+		if long {
+			err = fmt.Errorf("%w: line longer than %d bytes", ErrInvalidFormat, maxStreamLine)
+			line += "..."
+		} else {
+			err = ParseInto(line, &p)
+		}
+		if err != nil {
+			rep.Invalid++
+			if _, werr := fmt.Fprintf(w, "%d: %s: %v\n", n, line, err); werr != nil {
+				return rep, werr
+			}
+			continue
+		}
+		rep.Valid++
+		if p.HasChecksum {
+			rep.WithChecksum++
+		}
+		t := p.Time()
+		if rep.Valid == 1 || t.Before(rep.MinTime) {
+			rep.MinTime = t
+		}
+		if rep.Valid == 1 || t.After(rep.MaxTime) {
+			rep.MaxTime = t
+		}
+	}
+}
+
+// readLine returns the next line of br without its line ending, keeping at
+// most limit bytes; long reports whether the line was longer. It returns
+// io.EOF once br is exhausted.
+func readLine(br *bufio.Reader, limit int) (line []byte, long bool, err error) {
+	for {
+		frag, more, err := br.ReadLine()
+		if err != nil {
+			if err == io.EOF && (len(line) > 0 || long) {
+				err = nil
+			}
+			return line, long, err
+		}
+		if room := limit - len(line); len(frag) > room {
+			frag, long = frag[:room], true
+		}
+		line = append(line, frag...)
+		if !more {
+			return line, long, nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package orderlyid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateStream(t *testing.T) {
+	mk := func(ms int64, checksum bool) string {
+		id, err := NewFromParts(Components{Prefix: "order", TimeMs: ms, Random60: uint64(ms)}, checksum)
+		if err != nil {
+			t.Fatalf("NewFromParts: %v", err)
+		}
+		return id
+	}
+	early, late := int64(1735689600000), int64(1735776000000)
+	withCS := mk(late, true)
+	bad := withCS[:len(withCS)-1] + "0"
+	if strings.HasSuffix(withCS, "0") {
+		bad = withCS[:len(withCS)-1] + "1"
+	}
+	in := strings.Join([]string{
+		mk(early+5, false),
+		"",
+		"  " + mk(early, true) + "  ",
+		"order_123",
+		withCS,
+		bad,
+		mk(late-1, false),
+	}, "\n")
+
+	var out strings.Builder
+	rep, err := ValidateStream(strings.NewReader(in), &out)
+	if err != nil {
+		t.Fatalf("ValidateStream: %v", err)
+	}
+	want := Report{
+		Lines:        6,
+		Valid:        4,
+		Invalid:      2,
+		WithChecksum: 2,
+		MinTime:      time.UnixMilli(early).UTC(),
+		MaxTime:      time.UnixMilli(late).UTC(),
+	}
+	if rep != want {
+		t.Fatalf("report = %+v, want %+v", rep, want)
+	}
+	if got := rep.ChecksumCoverage(); got != 0.5 {
+		t.Fatalf("ChecksumCoverage = %v, want 0.5", got)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("invalid output has %d lines, want 2:\n%s", len(lines), out.String())
+	}
+	if !strings.HasPrefix(lines[0], "4: order_123: ") || !strings.Contains(lines[0], "payload length") {
+		t.Fatalf("unexpected first invalid line %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "6: "+bad+": ") || !strings.Contains(lines[1], "checksum") {
+		t.Fatalf("unexpected second invalid line %q", lines[1])
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestValidateStreamWriteError(t *testing.T) {
+	rep, err := ValidateStream(strings.NewReader("bogus\n"), failingWriter{})
+	if err == nil || rep.Invalid != 1 {
+		t.Fatalf("ValidateStream = %+v, %v; want the write error", rep, err)
+	}
+}
+
+func TestValidateStreamLongLine(t *testing.T) {
+	id := New("order")
+	long := strings.Repeat("x", 100<<10)
+	in := id + "\n" + long + "\r\n" + id
+
+	var out strings.Builder
+	rep, err := ValidateStream(strings.NewReader(in), &out)
+	if err != nil {
+		t.Fatalf("ValidateStream: %v", err)
+	}
+	if rep.Lines != 3 || rep.Valid != 2 || rep.Invalid != 1 {
+		t.Fatalf("report = %+v, want 3 lines, 2 valid, 1 invalid", rep)
+	}
+	want := "2: " + long[:maxStreamLine] + "...: "
+	if got := out.String(); !strings.HasPrefix(got, want) || !strings.Contains(got, "line longer than") {
+		t.Fatalf("unexpected invalid output %.80q...", got)
+	}
+}
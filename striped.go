@@ -0,0 +1,75 @@
+package orderlyid
+
+import (
+	"math/bits"
+	"runtime"
+	"sync/atomic"
+)
+
+// maxStripes bounds the number of stripes so that stripe ids fit in the low
+// 8 bits of the shard field.
+const maxStripes = 256
+
+// StripedGenerator spreads generation over several independent Generators,
+// one per logical processor, so that concurrent New calls rarely wait on the
+// same lock. Calls are assigned to stripes round-robin.
+//
+// Each stripe keeps its own sequence, so two stripes may mint IDs with the
+// same timestamp and sequence. To keep such IDs distinct, the stripe id is
+// written into the low bits of the shard field: with n stripes, the low
+// log2(n) bits of any shard passed to New are replaced. Use NumStripes to
+// learn how many bits are taken. Under LayoutExtended the whole shard field
+// is random instead, and stripe ids are not written.
+//
+// A StripedGenerator is safe for concurrent use.
+type StripedGenerator struct {
+	stripes []*Generator
+	mask    uint16
+	next    atomic.Uint32
+}
+
+// NewStripedGenerator returns a StripedGenerator with one stripe per logical
+// processor, rounded up to a power of two and capped at 256. Every stripe is
+// configured with opts. Because stripes read the entropy source concurrently,
+// a reader passed with WithEntropy must be safe for concurrent use.
+func NewStripedGenerator(opts ...GeneratorOption) *StripedGenerator {
+	return newStripedGenerator(runtime.NumCPU(), opts...)
+}
+
+func newStripedGenerator(n int, opts ...GeneratorOption) *StripedGenerator {
+	n = min(max(n, 1), maxStripes)
+	n = 1 << bits.Len(uint(n-1)) // round up to a power of two
+	s := &StripedGenerator{
+		stripes: make([]*Generator, n),
+		mask:    uint16(n - 1),
+	}
+	for i := range s.stripes {
+		s.stripes[i] = NewGenerator(opts...)
+	}
+	return s
+}
+
+// NumStripes reports the number of stripes. It is always a power of two; the
+// low log2(NumStripes()) shard bits of generated IDs hold the stripe id.
+func (s *StripedGenerator) NumStripes() int {
+	return len(s.stripes)
+}
+
+// New generates a new OrderlyID on the next stripe. It behaves like
+// Generator.New except for the stripe id in the shard field.
+func (s *StripedGenerator) New(prefix string, opts ...Option) string {
+	id, err := s.NewE(prefix, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NewE is like New but returns an error instead of panicking.
+func (s *StripedGenerator) NewE(prefix string, opts ...Option) (string, error) {
+	i := uint16(s.next.Add(1)-1) & s.mask
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.shard = o.shard&^s.mask | i
+	})
+	return s.stripes[i].NewE(prefix, opts...)
+}
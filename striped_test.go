@@ -0,0 +1,88 @@
+package orderlyid
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStripedGeneratorStripeCount(t *testing.T) {
+	for _, tt := range []struct{ n, want int }{{0, 1}, {1, 1}, {3, 4}, {8, 8}, {1000, 256}} {
+		if got := newStripedGenerator(tt.n).NumStripes(); got != tt.want {
+			t.Fatalf("newStripedGenerator(%d).NumStripes() = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestStripedGeneratorEmbedsStripe(t *testing.T) {
+	// With a frozen clock every stripe starts its sequence at 0, so only the
+	// stripe bits in the shard keep the IDs apart.
+	s := newStripedGenerator(4,
+		WithClock(fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))),
+		WithEntropy(repeatReader{0}),
+	)
+	seen := make(map[string]bool)
+	for i := 0; i < 8; i++ {
+		id := s.New("order", WithShard(0xFFF0))
+		if seen[id] {
+			t.Fatalf("duplicate id %s", id)
+		}
+		seen[id] = true
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if want := uint16(0xFFF0 | i%4); p.Shard != want {
+			t.Fatalf("call %d: shard = %#x, want %#x", i, p.Shard, want)
+		}
+	}
+}
+
+func TestStripedGeneratorConcurrentUnique(t *testing.T) {
+	// Constant entropy leaves only time, sequence, and stripe to separate IDs;
+	// 1000 IDs per stripe cannot wrap a stripe's 12-bit sequence.
+	s := newStripedGenerator(8, WithEntropy(repeatReader{0}))
+	const workers, perWorker = 16, 500
+	results := make([][]string, workers)
+	var wg sync.WaitGroup
+	for w := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, perWorker)
+			for i := range ids {
+				ids[i] = s.New("order")
+			}
+			results[w] = ids
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, workers*perWorker)
+	for _, ids := range results {
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("duplicate id %s", id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func BenchmarkNewParallel(b *testing.B) {
+	g := NewGenerator()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			g.New("order")
+		}
+	})
+}
+
+func BenchmarkStripedNewParallel(b *testing.B) {
+	s := NewStripedGenerator()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.New("order")
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/orderlykit/orderlyid"
+)
+
+const (
+	epoch2020      = int64(1577836800000)
+	prefixAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// runFuzz generates n random valid component sets, encodes each with
+// NewFromPartsHex, parses the result, and checks that every field round-trips.
+// It returns the number of passing and failing cases and the failure messages.
+func runFuzz(n int, seed int64) (okCount int, failures []string) {
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < n; i++ {
+		c := randomComponents(r)
+		randomHex := fmt.Sprintf("%016x", r.Uint64())
+		withChecksum := r.Intn(2) == 0
+		if msg := checkRoundTrip(c, randomHex, withChecksum); msg != "" {
+			failures = append(failures, fmt.Sprintf("[fuzz %d] %s", i, msg))
+			continue
+		}
+		okCount++
+	}
+	return okCount, failures
+}
+
+func randomComponents(r *rand.Rand) orderlyid.Components {
+	var b strings.Builder
+	b.WriteByte(prefixAlphabet[r.Intn(26)])
+	for j, n := 0, 1+r.Intn(30); j < n; j++ {
+		b.WriteByte(prefixAlphabet[r.Intn(len(prefixAlphabet))])
+	}
+	return orderlyid.Components{
+		Prefix: b.String(),
+		TimeMs: epoch2020 + r.Int63n(1<<48),
+		Flags:  uint8(r.Intn(256)) &^ orderlyid.FlagVersionMask,
+		Tenant: uint16(r.Intn(1 << 16)),
+		Seq:    uint16(r.Intn(1 << 12)),
+		Shard:  uint16(r.Intn(1 << 16)),
+	}
+}
+
+// checkRoundTrip returns a description of the first mismatch, or "" if the
+// components survive encode and parse unchanged.
+func checkRoundTrip(c orderlyid.Components, randomHex string, withChecksum bool) string {
+	id, err := orderlyid.NewFromPartsHex(c, randomHex, withChecksum)
+	if err != nil {
+		return fmt.Sprintf("encode %+v: %v", c, err)
+	}
+	p, err := orderlyid.Parse(id)
+	if err != nil {
+		return fmt.Sprintf("parse %s: %v", id, err)
+	}
+	wantRnd, err := hexTo60(randomHex)
+	if err != nil {
+		return fmt.Sprintf("random_hex %s: %v", randomHex, err)
+	}
+	switch {
+	case p.Prefix != c.Prefix:
+		return fmt.Sprintf("%s prefix mismatch: got=%s want=%s", id, p.Prefix, c.Prefix)
+	case p.TimeMs != c.TimeMs:
+		return fmt.Sprintf("%s time_ms mismatch: got=%d want=%d", id, p.TimeMs, c.TimeMs)
+	case p.Flags != c.Flags:
+		return fmt.Sprintf("%s flags mismatch: got=0x%02x want=0x%02x", id, p.Flags, c.Flags)
+	case p.Tenant != c.Tenant:
+		return fmt.Sprintf("%s tenant mismatch: got=%d want=%d", id, p.Tenant, c.Tenant)
+	case p.Seq != c.Seq:
+		return fmt.Sprintf("%s seq mismatch: got=%d want=%d", id, p.Seq, c.Seq)
+	case p.Shard != c.Shard:
+		return fmt.Sprintf("%s shard mismatch: got=%d want=%d", id, p.Shard, c.Shard)
+	case p.Random != wantRnd:
+		return fmt.Sprintf("%s random60 mismatch: got=0x%x want=0x%x", id, p.Random, wantRnd)
+	case p.HasChecksum != withChecksum:
+		return fmt.Sprintf("%s checksum presence mismatch: got=%v want=%v", id, p.HasChecksum, withChecksum)
+	}
+	return ""
+}
@@ -0,0 +1,13 @@
+package main
+
+import "testing"
+
+func TestRunFuzzSmoke(t *testing.T) {
+	okCount, failures := runFuzz(500, 1)
+	if len(failures) > 0 {
+		t.Fatalf("fuzz mode reported %d failures, first: %s", len(failures), failures[0])
+	}
+	if okCount != 500 {
+		t.Fatalf("fuzz mode checked %d cases, want 500", okCount)
+	}
+}
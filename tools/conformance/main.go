@@ -26,17 +26,42 @@ type vector struct {
 	Shard       uint16 `json:"shard"`
 	RandomHex   string `json:"random_hex"` // big-endian; we mask to 60 bits
 	ID          string `json:"id"`         // canonical string (may have checksum)
+	ChecksumLen int    `json:"checksum_len,omitempty"`
 	ExpectError bool   `json:"expect_error"`
 }
 
+// checksumLen returns the checksum length of vc: its checksum_len, or for
+// files that predate that field, 4 if the id has a suffix.
+func (vc vector) checksumLen() int {
+	if vc.ChecksumLen == 0 && strings.Contains(vc.ID, "-") {
+		return 4
+	}
+	return vc.ChecksumLen
+}
+
 var (
 	vectorsPath = flag.String("vectors", filepath.Join("spec", "test-vectors.json"), "path to spec/test-vectors.json")
 	verbose     = flag.Bool("v", false, "verbose output")
 	failFast    = flag.Bool("fail-fast", false, "stop on first failure")
+	fuzzN       = flag.Int("fuzz", 0, "additionally round-trip N random valid IDs")
+	fuzzSeed    = flag.Int64("seed", 1, "random seed for -fuzz")
+	mutate      = flag.Bool("mutate", false, "additionally derive negative cases from valid vectors and check they are rejected")
+	reportFmt   = flag.String("report", "", `report format: "" for human-readable lines, or "json"`)
+	reportOut   = flag.String("o", "-", "file to write the -report json result to, or - for stdout")
 )
 
+// rep collects results for -report json.
+var rep *report
+
 func main() {
 	flag.Parse()
+	switch *reportFmt {
+	case "":
+	case "json":
+		human = false
+	default:
+		die("unknown -report format %q: want json", *reportFmt)
+	}
 
 	data, err := os.ReadFile(*vectorsPath)
 	if err != nil {
@@ -51,37 +76,25 @@ func main() {
 		die("no vectors found in %s", *vectorsPath)
 	}
 
-	var encOK, encFail, parseOK, parseFail int
+	var t tally
+	rep = &report{Vectors: make([]vectorResult, 0, len(vf.Vectors))}
 
 	for i, vc := range vf.Vectors {
 		prefix := fmt.Sprintf("[%02d] %s", i, vc.Desc)
+		res := vectorResult{Index: i, Desc: vc.Desc, ID: vc.ID, Pass: true}
 
 		// -------- Encode check (only for valid cases) --------
 		if !vc.ExpectError {
-			withChecksum := strings.Contains(vc.ID, "-")
-			got, err := orderlyid.NewFromPartsHex(orderlyid.Components{
-				Prefix: vc.Prefix,
-				TimeMs: vc.TimeMs,
-				Flags:  vc.Flags,
-				Tenant: vc.Tenant,
-				Seq:    vc.Seq,
-				Shard:  vc.Shard,
-				// Random60 is set by hex in NewFromPartsHex
-			}, vc.RandomHex, withChecksum)
-			if err != nil {
-				encFail++
-				fail("%s encode error: %v", prefix, err)
-				if *failFast {
-					exitWith(encOK, encFail, parseOK, parseFail)
-				}
-			} else if got != vc.ID {
-				encFail++
-				fail("%s encode mismatch:\n  got:  %s\n  want: %s", prefix, got, vc.ID)
+			if msg := checkEncode(vc); msg != "" {
+				t.encFail++
+				res.Pass, res.Reasons = false, append(res.Reasons, msg)
+				fail("%s %s", prefix, msg)
 				if *failFast {
-					exitWith(encOK, encFail, parseOK, parseFail)
+					rep.Vectors = append(rep.Vectors, res)
+					exitWith(t)
 				}
 			} else {
-				encOK++
+				t.encOK++
 				if *verbose {
 					ok("%s encode ok", prefix)
 				}
@@ -89,79 +102,139 @@ func main() {
 		}
 
 		// -------- Parse check (all cases) --------
-		parsed, err := orderlyid.Parse(vc.ID)
-		if vc.ExpectError {
-			if err == nil {
-				parseFail++
-				fail("%s parse expected error, got none; id=%s", prefix, vc.ID)
-				if *failFast {
-					exitWith(encOK, encFail, parseOK, parseFail)
-				}
-			} else if *verbose {
-				ok("%s parse correctly failed: %v", prefix, err)
-				parseOK++
-			}
-			continue
-		}
-		if err != nil {
-			parseFail++
-			fail("%s parse error: %v", prefix, err)
+		if note, msg := checkParse(vc); msg != "" {
+			t.parseFail++
+			res.Pass, res.Reasons = false, append(res.Reasons, msg)
+			fail("%s %s", prefix, msg)
 			if *failFast {
-				exitWith(encOK, encFail, parseOK, parseFail)
+				rep.Vectors = append(rep.Vectors, res)
+				exitWith(t)
+			}
+		} else {
+			t.parseOK++
+			if *verbose {
+				ok("%s %s", prefix, note)
 			}
-			continue
 		}
+		rep.Vectors = append(rep.Vectors, res)
+	}
 
-		// Field checks (for valid vectors)
-		if parsed.Prefix != vc.Prefix {
-			parseFail++
-			fail("%s prefix mismatch: got=%s want=%s", prefix, parsed.Prefix, vc.Prefix)
-			continue
-		}
-		if parsed.TimeMs != vc.TimeMs {
-			parseFail++
-			fail("%s time_ms mismatch: got=%d want=%d", prefix, parsed.TimeMs, vc.TimeMs)
-			continue
-		}
-		if parsed.Flags != vc.Flags {
-			parseFail++
-			fail("%s flags mismatch: got=0x%02x want=0x%02x", prefix, parsed.Flags, vc.Flags)
-			continue
-		}
-		if parsed.Tenant != vc.Tenant {
-			parseFail++
-			fail("%s tenant mismatch: got=%d want=%d", prefix, parsed.Tenant, vc.Tenant)
-			continue
+	// -------- Fuzz round-trips (opt-in) --------
+	if *fuzzN > 0 {
+		okCount, failures := runFuzz(*fuzzN, *fuzzSeed)
+		t.fuzzOK, t.fuzzFail = okCount, len(failures)
+		rep.FuzzFailures = failures
+		for _, msg := range failures {
+			fail("%s", msg)
+			if *failFast {
+				exitWith(t)
+			}
 		}
-		if parsed.Seq != (vc.Seq & 0x0FFF) {
-			parseFail++
-			fail("%s seq mismatch: got=%d want=%d", prefix, parsed.Seq, vc.Seq&0x0FFF)
-			continue
+		if *verbose {
+			ok("fuzz: %d random round-trips (seed %d)", okCount, *fuzzSeed)
 		}
-		if parsed.Shard != vc.Shard {
-			parseFail++
-			fail("%s shard mismatch: got=%d want=%d", prefix, parsed.Shard, vc.Shard)
-			continue
+	}
+
+	// -------- Derived negative cases (opt-in) --------
+	if *mutate {
+		okCount, undetected, failures := runMutations(vf.Vectors)
+		t.mutOK, t.mutUndetected, t.mutFail = okCount, len(undetected), len(failures)
+		rep.MutateUndetected, rep.MutateFailures = undetected, failures
+		for _, msg := range undetected {
+			warn("%s (4-char checksum gap)", msg)
 		}
-		wantRnd, err := hexTo60(vc.RandomHex)
-		if err != nil {
-			parseFail++
-			fail("%s random_hex invalid: %v", prefix, err)
-			continue
+		for _, msg := range failures {
+			fail("%s", msg)
+			if *failFast {
+				exitWith(t)
+			}
 		}
-		if parsed.Random != wantRnd {
-			parseFail++
-			fail("%s random60 mismatch: got=0x%x want=0x%x", prefix, parsed.Random, wantRnd)
-			continue
+		if *verbose {
+			ok("mutate: %d derived negative cases rejected", okCount)
 		}
+	}
 
-		parseOK++
-		if *verbose {
-			ok("%s parse ok", prefix)
+	exitWith(t)
+}
+
+// checkEncode encodes the fields of a valid vector and returns a description
+// of the mismatch with its id, or "" if they agree.
+func checkEncode(vc vector) string {
+	got, err := encode(vc)
+	if err != nil {
+		return fmt.Sprintf("encode error: %v", err)
+	}
+	if got != vc.ID {
+		return fmt.Sprintf("encode mismatch:\n  got:  %s\n  want: %s", got, vc.ID)
+	}
+	return ""
+}
+
+// encode builds the ID for the fields of vc with its checksum length.
+func encode(vc vector) (string, error) {
+	id, err := orderlyid.NewFromPartsHex(orderlyid.Components{
+		Prefix: vc.Prefix,
+		TimeMs: vc.TimeMs,
+		Flags:  vc.Flags,
+		Tenant: vc.Tenant,
+		Seq:    vc.Seq,
+		Shard:  vc.Shard,
+		// Random60 is set by hex in NewFromPartsHex
+	}, vc.RandomHex, false)
+	n := vc.checksumLen()
+	if err != nil || n == 0 {
+		return id, err
+	}
+	// NewFromPartsHex only appends 4-char checksums; re-encode through
+	// Parsed to honor the vector's length.
+	p, err := orderlyid.Parse(id)
+	if err != nil {
+		return "", err
+	}
+	p.HasChecksum, p.ChecksumLen = true, n
+	return p.String()
+}
+
+// checkParse parses the id of vc and compares the result with the vector. It
+// returns a note for verbose output on success, or a description of the
+// failure.
+func checkParse(vc vector) (note, failure string) {
+	parsed, err := orderlyid.Parse(vc.ID)
+	if vc.ExpectError {
+		if err == nil {
+			return "", fmt.Sprintf("parse expected error, got none; id=%s", vc.ID)
 		}
+		return fmt.Sprintf("parse correctly failed: %v", err), ""
+	}
+	if err != nil {
+		return "", fmt.Sprintf("parse error: %v", err)
 	}
 
-	exitWith(encOK, encFail, parseOK, parseFail)
+	// Field checks (for valid vectors)
+	switch {
+	case parsed.Prefix != vc.Prefix:
+		return "", fmt.Sprintf("prefix mismatch: got=%s want=%s", parsed.Prefix, vc.Prefix)
+	case parsed.TimeMs != vc.TimeMs:
+		return "", fmt.Sprintf("time_ms mismatch: got=%d want=%d", parsed.TimeMs, vc.TimeMs)
+	case parsed.Flags != vc.Flags:
+		return "", fmt.Sprintf("flags mismatch: got=0x%02x want=0x%02x", parsed.Flags, vc.Flags)
+	case parsed.Tenant != vc.Tenant:
+		return "", fmt.Sprintf("tenant mismatch: got=%d want=%d", parsed.Tenant, vc.Tenant)
+	case parsed.Seq != (vc.Seq & 0x0FFF):
+		return "", fmt.Sprintf("seq mismatch: got=%d want=%d", parsed.Seq, vc.Seq&0x0FFF)
+	case parsed.Shard != vc.Shard:
+		return "", fmt.Sprintf("shard mismatch: got=%d want=%d", parsed.Shard, vc.Shard)
+	case parsed.ChecksumLen != vc.checksumLen():
+		return "", fmt.Sprintf("checksum_len mismatch: got=%d want=%d", parsed.ChecksumLen, vc.checksumLen())
+	}
+	wantRnd, err := hexTo60(vc.RandomHex)
+	if err != nil {
+		return "", fmt.Sprintf("random_hex invalid: %v", err)
+	}
+	if parsed.Random != wantRnd {
+		return "", fmt.Sprintf("random60 mismatch: got=0x%x want=0x%x", parsed.Random, wantRnd)
+	}
+	return "parse ok", ""
 }
 
 func hexTo60(h string) (uint64, error) {
@@ -176,13 +249,58 @@ func hexTo60(h string) (uint64, error) {
 	return u & ((1 << 60) - 1), nil
 }
 
-func ok(format string, args ...any)   { fmt.Printf("✓ "+format+"\n", args...) }
-func fail(format string, args ...any) { fmt.Printf("✗ "+format+"\n", args...) }
-func die(format string, args ...any)  { fmt.Fprintf(os.Stderr, format+"\n", args...); os.Exit(2) }
+// human is false when -report selects machine-readable output, which then
+// replaces the ✓/✗ lines and totals.
+var human = true
+
+func ok(format string, args ...any) {
+	if human {
+		fmt.Printf("✓ "+format+"\n", args...)
+	}
+}
+
+func fail(format string, args ...any) {
+	if human {
+		fmt.Printf("✗ "+format+"\n", args...)
+	}
+}
+
+func warn(format string, args ...any) {
+	if human {
+		fmt.Printf("! "+format+"\n", args...)
+	}
+}
+
+func die(format string, args ...any) { fmt.Fprintf(os.Stderr, format+"\n", args...); os.Exit(2) }
 
-func exitWith(encOK, encFail, parseOK, parseFail int) {
-	fmt.Printf("\nEncode: %d ok, %d fail\nParse:  %d ok, %d fail\n", encOK, encFail, parseOK, parseFail)
-	if encFail+parseFail > 0 {
+// tally counts check outcomes across all modes.
+type tally struct {
+	encOK, encFail     int
+	parseOK, parseFail int
+	fuzzOK, fuzzFail   int
+	mutOK, mutFail     int
+	mutUndetected      int // accepted in the unchecked tail of a 4-char checksum
+}
+
+func exitWith(t tally) {
+	if !human {
+		rep.Totals = t.totals()
+		if err := writeReport(*reportOut, rep); err != nil {
+			die("write report: %v", err)
+		}
+		if !rep.Totals.Pass {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	fmt.Printf("\nEncode: %d ok, %d fail\nParse:  %d ok, %d fail\n", t.encOK, t.encFail, t.parseOK, t.parseFail)
+	if t.fuzzOK+t.fuzzFail > 0 {
+		fmt.Printf("Fuzz:   %d ok, %d fail\n", t.fuzzOK, t.fuzzFail)
+	}
+	if t.mutOK+t.mutFail+t.mutUndetected > 0 {
+		fmt.Printf("Mutate: %d ok, %d fail, %d undetected\n", t.mutOK, t.mutFail, t.mutUndetected)
+	}
+	if t.encFail+t.parseFail+t.fuzzFail+t.mutFail > 0 {
 		os.Exit(1)
 	}
 	os.Exit(0)
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/orderlykit/orderlyid"
+)
+
+const alphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// uncheckedTail is the number of trailing payload symbols the 4-char checksum
+// cannot protect. The checksum keeps only the low 20 bits of the 30-bit
+// polymod residue, and a change to either of the last two payload symbols
+// lands entirely in the discarded high bits. The 6- and 8-char checksums
+// cover every symbol.
+const uncheckedTail = 2
+
+// mutation is a negative case derived from a valid ID.
+type mutation struct {
+	desc string
+	id   string
+	// unchecked is set for substitutions in the uncheckedTail of an ID with
+	// a 4-char checksum, which Parse is known to accept.
+	unchecked bool
+}
+
+// mutations derives IDs that Parse must reject from a valid canonical id:
+// an invalid symbol in the payload, a truncated payload, and, when id carries
+// a checksum, every single-symbol substitution in the payload and checksum.
+// Substitutions on unchecksummed IDs are skipped because they yield a
+// different but valid ID.
+func mutations(id string) []mutation {
+	base, cs, hasChecksum := strings.Cut(id, "-")
+	sep := strings.IndexByte(base, '_')
+	if sep <= 0 {
+		return nil
+	}
+	payloadStart := sep + 1
+	suffix := ""
+	if hasChecksum {
+		suffix = "-" + cs
+	}
+
+	out := []mutation{
+		{desc: "invalid symbol in payload", id: base[:payloadStart] + "!" + base[payloadStart+1:] + suffix},
+		{desc: "truncated payload", id: base[:len(base)-1] + suffix},
+	}
+	if !hasChecksum {
+		return out
+	}
+	for i := payloadStart; i < len(base); i++ {
+		out = append(out, mutation{
+			desc:      fmt.Sprintf("payload symbol %d substituted", i-payloadStart),
+			id:        base[:i] + string(nextSymbol(base[i])) + base[i+1:] + suffix,
+			unchecked: len(cs) == 4 && i >= len(base)-uncheckedTail,
+		})
+	}
+	for i := 0; i < len(cs); i++ {
+		out = append(out, mutation{
+			desc: fmt.Sprintf("checksum symbol %d substituted", i),
+			id:   base + "-" + cs[:i] + string(nextSymbol(cs[i])) + cs[i+1:],
+		})
+	}
+	return out
+}
+
+// nextSymbol returns the canonical symbol whose value follows c's.
+func nextSymbol(c byte) byte {
+	i := strings.IndexByte(alphabet, c)
+	return alphabet[(i+1)%len(alphabet)]
+}
+
+// runMutations checks that Parse rejects every mutation of every valid vector.
+// Accepted mutations in the unchecked tail of a 4-char checksum are returned
+// as undetected rather than as failures: they are the documented limit of
+// that checksum, not a bug in the implementation under test.
+func runMutations(vectors []vector) (okCount int, undetected, failures []string) {
+	for i, vc := range vectors {
+		if vc.ExpectError {
+			continue
+		}
+		for _, m := range mutations(vc.ID) {
+			if _, err := orderlyid.Parse(m.id); err == nil {
+				msg := fmt.Sprintf("[%02d] %s: %s accepted %s", i, vc.Desc, m.desc, m.id)
+				if m.unchecked {
+					undetected = append(undetected, msg)
+				} else {
+					failures = append(failures, msg)
+				}
+				continue
+			}
+			okCount++
+		}
+	}
+	return okCount, undetected, failures
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/orderlykit/orderlyid"
+)
+
+func TestMutationsAreRejected(t *testing.T) {
+	for _, n := range []int{0, 4, 6, 8} {
+		opts := []orderlyid.Option{orderlyid.WithTenant(5)}
+		if n > 0 {
+			opts = append(opts, orderlyid.WithChecksumLen(n))
+		}
+		id := orderlyid.New("order", opts...)
+		ms := mutations(id)
+		want := 2
+		if n > 0 {
+			want += 32 + n
+		}
+		if len(ms) != want {
+			t.Fatalf("checksum %d: got %d mutations, want %d", n, len(ms), want)
+		}
+		for _, m := range ms {
+			if m.id == id {
+				t.Fatalf("%s did not change %s", m.desc, id)
+			}
+			_, err := orderlyid.Parse(m.id)
+			switch {
+			case m.unchecked && n != 4:
+				t.Fatalf("checksum %d: %s marked unchecked", n, m.desc)
+			case m.unchecked && err == nil:
+				// The documented 4-char gap.
+			case err == nil:
+				t.Fatalf("checksum %d: %s not detected: %s", n, m.desc, m.id)
+			}
+		}
+	}
+}
+
+func TestRunMutationsReportsUncheckedTail(t *testing.T) {
+	vs := []vector{
+		{Desc: "valid", ID: orderlyid.New("order", orderlyid.WithChecksum(true))},
+		{Desc: "invalid", ID: "order_123", ExpectError: true},
+		{Desc: "valid 6", ID: orderlyid.New("order", orderlyid.WithChecksumLen(6))},
+	}
+	okCount, undetected, failures := runMutations(vs)
+	if len(failures) > 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	if len(undetected) != uncheckedTail {
+		t.Fatalf("undetected = %v, want the last %d payload symbols of the 4-char vector", undetected, uncheckedTail)
+	}
+	if want := (2 + 32 - uncheckedTail + 4) + (2 + 32 + 6); okCount != want {
+		t.Fatalf("checked %d mutations, want %d", okCount, want)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// report is the -report json result. Other implementations can produce the
+// same shape and diff it against the Go reference in CI.
+type report struct {
+	Vectors        []vectorResult `json:"vectors"`
+	FuzzFailures   []string       `json:"fuzz_failures,omitempty"`
+	MutateFailures []string       `json:"mutate_failures,omitempty"`
+	// MutateUndetected lists substitutions in the last two payload symbols
+	// of IDs with a 4-char checksum, which that checksum cannot detect.
+	MutateUndetected []string `json:"mutate_undetected,omitempty"`
+	Totals           totals   `json:"totals"`
+}
+
+// vectorResult is the outcome of the encode and parse checks for one vector.
+type vectorResult struct {
+	Index   int      `json:"index"`
+	Desc    string   `json:"desc"`
+	ID      string   `json:"id"`
+	Pass    bool     `json:"pass"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+type totals struct {
+	EncodeOK   int `json:"encode_ok"`
+	EncodeFail int `json:"encode_fail"`
+	ParseOK    int `json:"parse_ok"`
+	ParseFail  int `json:"parse_fail"`
+	FuzzOK     int `json:"fuzz_ok"`
+	FuzzFail   int `json:"fuzz_fail"`
+	MutateOK   int `json:"mutate_ok"`
+	MutateFail int `json:"mutate_fail"`
+	// MutateUndetected counts accepted mutations the 4-char checksum cannot
+	// detect; they do not affect Pass.
+	MutateUndetected int  `json:"mutate_undetected"`
+	Pass             bool `json:"pass"`
+}
+
+func (t tally) totals() totals {
+	return totals{
+		EncodeOK:         t.encOK,
+		EncodeFail:       t.encFail,
+		ParseOK:          t.parseOK,
+		ParseFail:        t.parseFail,
+		FuzzOK:           t.fuzzOK,
+		FuzzFail:         t.fuzzFail,
+		MutateOK:         t.mutOK,
+		MutateFail:       t.mutFail,
+		MutateUndetected: t.mutUndetected,
+		Pass:             t.encFail+t.parseFail+t.fuzzFail+t.mutFail == 0,
+	}
+}
+
+// writeReport writes r as indented JSON to path, or to stdout if path is "-".
+func writeReport(path string, r *report) error {
+	if path == "-" {
+		return encodeReport(os.Stdout, r)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encodeReport(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func encodeReport(w io.Writer, r *report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
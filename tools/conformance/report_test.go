@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/orderlykit/orderlyid"
+)
+
+func TestChecksReportReasons(t *testing.T) {
+	id := orderlyid.New("order", orderlyid.WithTenant(3), orderlyid.WithChecksum(true))
+	p, err := orderlyid.Parse(id)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	good := vector{
+		Desc:      "generated",
+		Prefix:    p.Prefix,
+		TimeMs:    p.TimeMs,
+		Tenant:    p.Tenant,
+		Seq:       p.Seq,
+		RandomHex: p.RandomHex(),
+		ID:        id,
+	}
+	if msg := checkEncode(good); msg != "" {
+		t.Fatalf("checkEncode: %s", msg)
+	}
+	if _, msg := checkParse(good); msg != "" {
+		t.Fatalf("checkParse: %s", msg)
+	}
+
+	bad := good
+	bad.Tenant = 4
+	if msg := checkEncode(bad); msg == "" {
+		t.Fatalf("checkEncode accepted a wrong tenant")
+	}
+	if _, msg := checkParse(bad); msg != "tenant mismatch: got=3 want=4" {
+		t.Fatalf("checkParse reason = %q", msg)
+	}
+	if note, msg := checkParse(vector{ID: "order_123", ExpectError: true}); msg != "" || note == "" {
+		t.Fatalf("expected-error vector: note %q, failure %q", note, msg)
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	r := &report{
+		Vectors: []vectorResult{
+			{Index: 0, Desc: "ok", ID: "order_x", Pass: true},
+			{Index: 1, Desc: "bad", ID: "order_y", Reasons: []string{"parse error: boom"}},
+		},
+		Totals: tally{encOK: 1, parseOK: 1, parseFail: 1}.totals(),
+	}
+	var buf bytes.Buffer
+	if err := encodeReport(&buf, r); err != nil {
+		t.Fatalf("encodeReport: %v", err)
+	}
+	var got struct {
+		Vectors []map[string]any `json:"vectors"`
+		Totals  map[string]any   `json:"totals"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if len(got.Vectors) != 2 || got.Vectors[1]["pass"] != false {
+		t.Fatalf("vectors = %v", got.Vectors)
+	}
+	if _, ok := got.Vectors[0]["reasons"]; ok {
+		t.Fatalf("passing vector has reasons: %v", got.Vectors[0])
+	}
+	if got.Totals["parse_fail"] != float64(1) || got.Totals["pass"] != false {
+		t.Fatalf("totals = %v", got.Totals)
+	}
+}
@@ -0,0 +1,61 @@
+package orderlyid
+
+import "fmt"
+
+// Prefix is the constraint implemented by marker types that bind a TypedID to
+// a fixed prefix. Implementations are usually empty structs:
+//
+//	type OrderPrefix struct{}
+//
+//	func (OrderPrefix) Prefix() string { return "order" }
+//
+//	type OrderID = orderlyid.TypedID[OrderPrefix]
+type Prefix interface {
+	Prefix() string
+}
+
+// TypedID is an OrderlyID string whose prefix is fixed by T. Two TypedIDs with
+// different marker types are distinct types and cannot be assigned to each
+// other without an explicit conversion.
+type TypedID[T Prefix] string
+
+// String returns the ID in its textual form.
+func (id TypedID[T]) String() string {
+	return string(id)
+}
+
+// Parse decodes the ID. It fails if the ID does not carry T's prefix.
+func (id TypedID[T]) Parse() (*Parsed, error) {
+	return parseTyped[T](string(id))
+}
+
+// NewTyped generates a new ID with T's prefix. It panics under the same
+// conditions as New.
+func NewTyped[T Prefix](opts ...Option) TypedID[T] {
+	var t T
+	return TypedID[T](New(t.Prefix(), opts...))
+}
+
+// ParseTyped validates s as an ID with T's prefix and returns it as a
+// TypedID.
+//
+// ParseTyped may return any error returned by Parse, or an error wrapping
+// ErrInvalidPrefix if the prefix does not match T.
+func ParseTyped[T Prefix](s string) (TypedID[T], error) {
+	if _, err := parseTyped[T](s); err != nil {
+		return "", err
+	}
+	return TypedID[T](s), nil
+}
+
+func parseTyped[T Prefix](s string) (*Parsed, error) {
+	p, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	var t T
+	if want := t.Prefix(); p.Prefix != want {
+		return nil, fmt.Errorf("%w: got %q, want %q", ErrInvalidPrefix, p.Prefix, want)
+	}
+	return p, nil
+}
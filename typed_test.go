@@ -0,0 +1,57 @@
+package orderlyid
+
+import (
+	"errors"
+	"testing"
+)
+
+type orderPrefix struct{}
+
+func (orderPrefix) Prefix() string { return "order" }
+
+type userPrefix struct{}
+
+func (userPrefix) Prefix() string { return "user" }
+
+type (
+	orderID = TypedID[orderPrefix]
+	userID  = TypedID[userPrefix]
+)
+
+// lookupOrder only accepts order IDs; passing a userID does not compile.
+func lookupOrder(id orderID) string { return id.String() }
+
+func TestTypedIDRoundTrip(t *testing.T) {
+	id := NewTyped[orderPrefix](WithTenant(3))
+	if got := lookupOrder(id); got != string(id) {
+		t.Fatalf("lookupOrder = %s, want %s", got, id)
+	}
+	p, err := id.Parse()
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Prefix != "order" || p.Tenant != 3 {
+		t.Fatalf("unexpected parse result: %+v", p)
+	}
+
+	back, err := ParseTyped[orderPrefix](string(id))
+	if err != nil {
+		t.Fatalf("ParseTyped: %v", err)
+	}
+	if back != id {
+		t.Fatalf("ParseTyped = %s, want %s", back, id)
+	}
+}
+
+func TestTypedIDRejectsPrefixMismatch(t *testing.T) {
+	u := NewTyped[userPrefix]()
+	if _, err := ParseTyped[orderPrefix](string(u)); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+	// A typed ID forced into the wrong type is still caught on Parse.
+	forged := orderID(u)
+	if _, err := forged.Parse(); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("expected ErrInvalidPrefix, got %v", err)
+	}
+	var _ userID = u
+}
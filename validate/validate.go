@@ -0,0 +1,62 @@
+// Package validate registers OrderlyID checks with
+// github.com/go-playground/validator.
+//
+// After calling RegisterValidation, struct fields can be annotated with:
+//
+//	type Order struct {
+//		ID       string `validate:"orderlyid"`
+//		Customer string `validate:"orderlyid_prefix=user"`
+//	}
+//
+// The "orderlyid" tag accepts any string that orderlyid.Parse accepts,
+// including a verified checksum suffix. The "orderlyid_prefix" tag additionally
+// requires the parsed prefix to equal the tag parameter. Combine either tag with
+// "omitempty" to allow empty values.
+//
+// The package is a separate module, so that the orderlyid module itself does
+// not depend on the validator library.
+package validate
+
+import (
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/orderlykit/orderlyid"
+)
+
+const (
+	// Tag is the validator tag for any valid OrderlyID.
+	Tag = "orderlyid"
+	// PrefixTag is the validator tag for an OrderlyID with a specific prefix.
+	PrefixTag = "orderlyid_prefix"
+)
+
+// RegisterValidation adds the Tag and PrefixTag validations to v.
+func RegisterValidation(v *validator.Validate) error {
+	if err := v.RegisterValidation(Tag, validateID); err != nil {
+		return err
+	}
+	return v.RegisterValidation(PrefixTag, validatePrefix)
+}
+
+func validateID(fl validator.FieldLevel) bool {
+	_, ok := parseField(fl)
+	return ok
+}
+
+func validatePrefix(fl validator.FieldLevel) bool {
+	p, ok := parseField(fl)
+	return ok && p.Prefix == fl.Param()
+}
+
+func parseField(fl validator.FieldLevel) (*orderlyid.Parsed, bool) {
+	f := fl.Field()
+	if f.Kind() != reflect.String {
+		return nil, false
+	}
+	p, err := orderlyid.Parse(f.String())
+	if err != nil {
+		return nil, false
+	}
+	return p, true
+}
@@ -0,0 +1,56 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/orderlykit/orderlyid"
+)
+
+type order struct {
+	ID       string `validate:"orderlyid"`
+	Customer string `validate:"orderlyid_prefix=user"`
+	Parent   string `validate:"omitempty,orderlyid_prefix=order"`
+}
+
+func TestRegisterValidation(t *testing.T) {
+	v := validator.New()
+	if err := RegisterValidation(v); err != nil {
+		t.Fatalf("RegisterValidation: %v", err)
+	}
+
+	good := order{
+		ID:       orderlyid.New("order", orderlyid.WithChecksum(true)),
+		Customer: orderlyid.New("user"),
+	}
+	if err := v.Struct(good); err != nil {
+		t.Fatalf("expected valid struct, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		mut   func(*order)
+		field string
+	}{
+		{name: "malformed id", mut: func(o *order) { o.ID = "order_123" }, field: "ID"},
+		{name: "bad checksum", mut: func(o *order) { o.ID = o.ID[:len(o.ID)-4] + "zzzz" }, field: "ID"},
+		{name: "wrong prefix", mut: func(o *order) { o.Customer = orderlyid.New("order") }, field: "Customer"},
+		{name: "empty without omitempty", mut: func(o *order) { o.Customer = "" }, field: "Customer"},
+		{name: "optional wrong prefix", mut: func(o *order) { o.Parent = orderlyid.New("user") }, field: "Parent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := good
+			tt.mut(&o)
+			err := v.Struct(o)
+			var verrs validator.ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+			if len(verrs) != 1 || verrs[0].Field() != tt.field {
+				t.Fatalf("expected single failure on %s, got %v", tt.field, verrs)
+			}
+		})
+	}
+}
@@ -23,11 +23,21 @@ type vector struct {
 	Tenant      uint16 `json:"tenant"`
 	Seq         uint16 `json:"seq"` // expect 0..4095
 	Shard       uint16 `json:"shard"`
-	RandomHex   string `json:"random_hex"` // 60 bits (we will mask lower 60)
-	ID          string `json:"id"`         // canonical id to compare; may include checksum suffix
+	RandomHex   string `json:"random_hex"`   // 60 bits (we will mask lower 60)
+	ID          string `json:"id"`           // canonical id to compare; may include checksum suffix
+	ChecksumLen int    `json:"checksum_len"` // 4, 6, or 8; absent in older files for 4
 	ExpectError bool   `json:"expect_error"`
 }
 
+// checksumLen returns the checksum length of v, treating an id with a suffix
+// but no checksum_len as a 4-char checksum.
+func (v vector) checksumLen() int {
+	if v.ChecksumLen == 0 && strings.Contains(v.ID, "-") {
+		return 4
+	}
+	return v.ChecksumLen
+}
+
 func loadVectors(t *testing.T) specVectors {
 	t.Helper()
 
@@ -48,17 +58,18 @@ func loadVectors(t *testing.T) specVectors {
 }
 
 // buildID reconstructs an OrderlyID string from vector fields.
-// It uses internal helpers (pack, b32encode, checksum4Base) since this test lives in the same package.
+// It uses internal helpers (pack, formatBody) since this test lives in the same package.
 func buildID(v vector) (string, error) {
-	withChecksum := strings.Contains(v.ID, "-")
-	return NewFromPartsHex(Components{
-		Prefix: v.Prefix,
-		TimeMs: v.TimeMs,
-		Flags:  v.Flags,
-		Tenant: v.Tenant,
-		Seq:    v.Seq,
-		Shard:  v.Shard,
-	}, v.RandomHex, withChecksum)
+	if err := validatePrefix(v.Prefix); err != nil {
+		return "", err
+	}
+	r, err := random60FromHex(v.RandomHex)
+	if err != nil {
+		return "", err
+	}
+	ms := uint64(max(v.TimeMs-epoch2020, 0))
+	body := pack(ms, v.Flags, v.Tenant, v.Seq&0x0FFF, v.Shard, r)
+	return formatBody(v.Prefix, body, v.checksumLen()), nil
 }
 
 func isInvalid(v vector) bool {
@@ -115,7 +126,7 @@ func TestSpecVectors_ParseMatches(t *testing.T) {
 		if parsed.TimeMs != vec.TimeMs {
 			t.Fatalf("[%s] time_ms mismatch: got=%d want=%d (%s vs %s)",
 				vec.Desc, parsed.TimeMs, vec.TimeMs,
-				time.UnixMilli(parsed.TimeMs).UTC().Format(time.RFC3339Nano),
+				parsed.Time().Format(time.RFC3339Nano),
 				time.UnixMilli(vec.TimeMs).UTC().Format(time.RFC3339Nano),
 			)
 		}
@@ -132,6 +143,9 @@ func TestSpecVectors_ParseMatches(t *testing.T) {
 		if parsed.Shard != vec.Shard {
 			t.Fatalf("[%s] shard mismatch: got=%d want=%d", vec.Desc, parsed.Shard, vec.Shard)
 		}
+		if parsed.ChecksumLen != vec.checksumLen() {
+			t.Fatalf("[%s] checksum_len mismatch: got=%d want=%d", vec.Desc, parsed.ChecksumLen, vec.checksumLen())
+		}
 		// Random: Parse exposes Random as 60-bit value. Compare masked.
 		wantRnd, err := hexTo60(vec.RandomHex)
 		if err != nil {
@@ -143,6 +157,114 @@ func TestSpecVectors_ParseMatches(t *testing.T) {
 	}
 }
 
+func TestSpecVectors_RandomHexMatches(t *testing.T) {
+	vset := loadVectors(t)
+	for _, vec := range vset.Vectors {
+		if vec.ExpectError {
+			continue
+		}
+		want, err := hexTo60(vec.RandomHex)
+		if err != nil {
+			t.Fatalf("[%s] random decode: %v", vec.Desc, err)
+		}
+		wantHex := Components{Random60: want}.RandomHex()
+		if len(wantHex) != 16 || (vec.RandomHex[0] == '0' && wantHex != vec.RandomHex) {
+			t.Fatalf("[%s] Components.RandomHex = %s, vector random_hex = %s", vec.Desc, wantHex, vec.RandomHex)
+		}
+		parsed, err := Parse(vec.ID)
+		if err != nil {
+			t.Fatalf("[%s] parse: %v", vec.Desc, err)
+		}
+		if got := parsed.RandomHex(); got != wantHex {
+			t.Fatalf("[%s] Parsed.RandomHex = %s, want %s", vec.Desc, got, wantHex)
+		}
+	}
+}
+
+func TestSpecVectors_ComponentsRoundTrip(t *testing.T) {
+	vset := loadVectors(t)
+	for _, vec := range vset.Vectors {
+		if vec.ExpectError || isInvalid(vec) {
+			continue
+		}
+		parsed, err := Parse(vec.ID)
+		if err != nil {
+			t.Fatalf("[%s] parse: %v", vec.Desc, err)
+		}
+		want, err := Normalize(vec.ID, ChecksumStrip)
+		if err != nil {
+			t.Fatalf("[%s] normalize: %v", vec.Desc, err)
+		}
+		got, err := NewFromParts(parsed.Components(), false)
+		if err != nil {
+			t.Fatalf("[%s] NewFromParts: %v", vec.Desc, err)
+		}
+		if got != want {
+			t.Fatalf("[%s] Components round trip:\n got: %s\nwant: %s", vec.Desc, got, want)
+		}
+		// NewFromParts only appends 4-char checksums.
+		if parsed.ChecksumLen > 4 {
+			continue
+		}
+		if got, err := NewFromParts(parsed.Components(), parsed.HasChecksum); err != nil || got != vec.ID {
+			t.Fatalf("[%s] Components round trip with checksum = %s, %v; want %s", vec.Desc, got, err, vec.ID)
+		}
+	}
+
+	// Modify one field and re-encode.
+	p := mustParse(t, vset.Vectors[0].ID)
+	c := p.Components()
+	c.Tenant++
+	id, err := NewFromParts(c, false)
+	if err != nil {
+		t.Fatalf("NewFromParts: %v", err)
+	}
+	q := mustParse(t, id)
+	if q.Tenant != p.Tenant+1 || q.TimeMs != p.TimeMs || q.Random != p.Random {
+		t.Fatalf("modified tenant round trip = %+v, from %+v", q, p)
+	}
+}
+
+type packedVector struct {
+	vector
+	BodyHex string `json:"body_hex"`
+}
+
+func TestSpecVectors_PackedBodiesDecode(t *testing.T) {
+	path := filepath.Join(".", "spec", "test-vectors-packed.json")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	var pv struct {
+		Vectors []packedVector `json:"vectors"`
+	}
+	if err := json.Unmarshal(b, &pv); err != nil {
+		t.Fatalf("unmarshal packed vectors: %v", err)
+	}
+	if len(pv.Vectors) == 0 {
+		t.Fatalf("no vectors found in %s", path)
+	}
+	for _, vec := range pv.Vectors {
+		body, err := hex.DecodeString(vec.BodyHex)
+		if err != nil || len(body) != 20 {
+			t.Fatalf("[%s] body_hex must be 20 bytes of hex: %q", vec.Desc, vec.BodyHex)
+		}
+		ms, flags, tenant, seq, shard, random := unpack(body)
+		wantRnd, err := hexTo60(vec.RandomHex)
+		if err != nil {
+			t.Fatalf("[%s] random decode: %v", vec.Desc, err)
+		}
+		if int64(ms)+epoch2020 != vec.TimeMs || flags != vec.Flags || tenant != vec.Tenant ||
+			seq != vec.Seq&0x0FFF || shard != vec.Shard || random != wantRnd {
+			t.Fatalf("[%s] body %s does not decode to the vector fields", vec.Desc, vec.BodyHex)
+		}
+		if got := b32encode(body); !strings.Contains(vec.ID, "_"+got) {
+			t.Fatalf("[%s] body encodes to %s, not the payload of %s", vec.Desc, got, vec.ID)
+		}
+	}
+}
+
 func hexTo60(h string) (uint64, error) {
 	b, err := hex.DecodeString(h)
 	if err != nil {